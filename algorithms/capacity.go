@@ -0,0 +1,137 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Resources of the capacity model besides the extended ones.
+const (
+	ResourceCPU    = "cpu"
+	ResourceMemory = "memory"
+	ResourcePods   = "pods"
+)
+
+// Excess is a resource a node is asked for more of than it has.
+type Excess struct {
+	Node      string `json:"node"`
+	Resource  string `json:"resource"`
+	Requested int    `json:"requested"`
+	Capacity  int    `json:"capacity"`
+}
+
+// Over returns how much more is requested than the node has.
+func (e Excess) Over() int {
+	return e.Requested - e.Capacity
+}
+
+// requests returns what one replica of service requests, by resource.
+func (p *Problem) requests(service string) map[string]int {
+	c := p.ServiceConstraints[service]
+	req := map[string]int{ResourceCPU: c.CPU, ResourceMemory: c.Memory, ResourcePods: 1}
+	for resource, amount := range c.Extended {
+		req[resource] = amount
+	}
+	return req
+}
+
+// capacity returns what node offers by resource, and false if the node has
+// no entry in p.NodeConstraints and so is unconstrained. A node without a
+// pod limit runs any number of replicas; an extended resource it does not
+// list has a capacity of 0.
+func (p *Problem) capacity(node string) (map[string]int, bool) {
+	c, ok := p.NodeConstraints[node]
+	if !ok {
+		return nil, false
+	}
+	capacity := map[string]int{ResourceCPU: c.CPU, ResourceMemory: c.Memory, ResourcePods: c.Pods}
+	if c.Pods == 0 {
+		capacity[ResourcePods] = math.MaxInt
+	}
+	for resource, amount := range c.Extended {
+		capacity[resource] = amount
+	}
+	return capacity, true
+}
+
+// requested returns what the replicas of solution on node request, by
+// resource.
+func (p *Problem) requested(solution map[string]map[string]int, node string) map[string]int {
+	total := make(map[string]int)
+	for _, service := range p.Topology.Services {
+		replicas := solution[node][service]
+		if replicas == 0 {
+			continue
+		}
+		for resource, amount := range p.requests(service) {
+			total[resource] += replicas * amount
+		}
+	}
+	return total
+}
+
+// Feasibility lists every resource a node of solution is asked for more of
+// than it has, by node in topology order and then by resource.
+func (p *Problem) Feasibility(solution map[string]map[string]int) []Excess {
+	var excess []Excess
+	for _, node := range p.Topology.NodeNames() {
+		capacity, ok := p.capacity(node)
+		if !ok {
+			continue
+		}
+		requested := p.requested(solution, node)
+		resources := make([]string, 0, len(requested))
+		for resource := range requested {
+			resources = append(resources, resource)
+		}
+		sort.Strings(resources)
+		for _, resource := range resources {
+			if requested[resource] > capacity[resource] {
+				excess = append(excess, Excess{node, resource, requested[resource], capacity[resource]})
+			}
+		}
+	}
+	return excess
+}
+
+// capacityViolations reports the Feasibility of solution as violations.
+func (p *Problem) capacityViolations(solution map[string]map[string]int) []Violation {
+	var violations []Violation
+	for _, e := range p.Feasibility(solution) {
+		violations = append(violations, Violation{
+			Rule:   "capacity of " + e.Node,
+			Detail: fmt.Sprintf("%s: %d requested of %d, %d over", e.Resource, e.Requested, e.Capacity, e.Over()),
+		})
+	}
+	return violations
+}
+
+// free returns what node has left under solution, by resource, and false if
+// it is unconstrained.
+func (p *Problem) free(solution map[string]map[string]int, node string) (map[string]int, bool) {
+	capacity, ok := p.capacity(node)
+	if !ok {
+		return nil, false
+	}
+	for resource, amount := range p.requested(solution, node) {
+		capacity[resource] -= amount
+	}
+	return capacity, true
+}
+
+// fits returns how many replicas requesting req fit into free.
+func fits(req, free map[string]int) int {
+	n := math.MaxInt
+	for resource, amount := range req {
+		if amount <= 0 {
+			continue
+		}
+		if left := free[resource]; left < amount {
+			return 0
+		} else if left/amount < n {
+			n = left / amount
+		}
+	}
+	return n
+}
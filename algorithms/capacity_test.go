@@ -0,0 +1,66 @@
+package algorithms
+
+import (
+	"optimizer/common"
+	"reflect"
+	"testing"
+)
+
+func TestFeasibility(t *testing.T) {
+	problem := repairProblem()
+	problem.ServiceConstraints["small"] = common.Constraints{CPU: 100, Memory: 10, Extended: map[string]int{"accelerators": 1}}
+	problem.NodeConstraints["edge1"] = common.Constraints{CPU: 1000, Memory: 100, Pods: 3, Extended: map[string]int{"accelerators": 1}}
+
+	solution := problem.NewSolution()
+	solution["edge1"]["front"] = 1
+	solution["edge1"]["small"] = 1
+	solution["cloud1"]["big"] = 1 // cloud1 is smaller than edge1 but fits its own load
+	if excess := problem.Feasibility(solution); len(excess) != 0 || !problem.checkConstraints(solution) {
+		t.Fatalf("feasible placement reported as %v", excess)
+	}
+
+	solution["edge1"]["small"] = 2 // a second accelerator and a fourth pod
+	solution["edge1"]["big"] = 1
+	solution["edge2"]["small"] = 1 // edge2 has no accelerators
+	want := []Excess{
+		{Node: "edge1", Resource: "accelerators", Requested: 2, Capacity: 1},
+		{Node: "edge1", Resource: ResourcePods, Requested: 4, Capacity: 3},
+		{Node: "edge2", Resource: "accelerators", Requested: 1, Capacity: 0},
+	}
+	if got := problem.Feasibility(solution); !reflect.DeepEqual(got, want) {
+		t.Errorf("Feasibility = %+v, want %+v", got, want)
+	}
+	if v := problem.Violations(solution); len(v) != 3 || v[0].String() != "capacity of edge1: accelerators: 2 requested of 1, 1 over" {
+		t.Errorf("Violations = %v", v)
+	}
+}
+
+func TestBestServerCountsEveryResource(t *testing.T) {
+	problem := repairProblem()
+	// edge1 has more CPU but memory for one replica only
+	problem.NodeConstraints["edge1"] = common.Constraints{CPU: 1000, Memory: 10}
+	tigo := NewTIGO(problem, 1, testRand())
+
+	node, n := tigo.bestServer(problem.NewSolution(), "small")
+	if node != "edge2" || n != 5 {
+		t.Errorf("bestServer = %s, %d; want edge2, 5", node, n)
+	}
+}
+
+func TestEdgeReplacementRunsOutOfNodes(t *testing.T) {
+	problem := repairProblem()
+	// edge1 has memory for two replicas of small, edge2 for none
+	problem.NodeConstraints["edge1"] = common.Constraints{CPU: 1000, Memory: 20}
+	problem.NodeConstraints["edge2"] = common.Constraints{CPU: 500, Memory: 5}
+	trace := common.Trace{}
+	for range 150 { // calculateNeeded asks for three replicas
+		trace.Spans = append(trace.Spans, common.Span{ServiceName: "small"})
+	}
+	problem.Traces = common.TraceData{Data: []common.Trace{trace}}
+	tigo := NewTIGO(problem, 1, testRand())
+
+	solution := tigo.edgeReplacement(problem.NewSolution())
+	if solution["edge1"]["small"] != 2 || solution["edge2"]["small"] != 0 {
+		t.Errorf("edgeReplacement = %v; want the two replicas that fit on edge1", solution)
+	}
+}
@@ -0,0 +1,109 @@
+package algorithms
+
+import (
+	"optimizer/common"
+	"sort"
+)
+
+// spanGraph is the call structure of one trace. The children of a span are
+// split into stages that run one after another; the spans within a stage
+// overlapped in the measured trace and are taken to run concurrently.
+type spanGraph struct {
+	roots  [][]int   // stages of the spans whose parent is not in the trace
+	stages [][][]int // stages[i] are the stages of the children of span i
+}
+
+// newSpanGraph links every span of trace to its CHILD_OF parent (or, lacking
+// one, its first reference) and groups siblings into stages by their
+// measured start and end times.
+func newSpanGraph(trace common.Trace) spanGraph {
+	index := make(map[string]int, len(trace.Spans))
+	for i, span := range trace.Spans {
+		index[span.SpanID] = i
+	}
+
+	children := make([][]int, len(trace.Spans))
+	var roots []int
+	for i, span := range trace.Spans {
+		parent, ok := -1, false
+		for _, ref := range span.References {
+			if j, found := index[ref.SpanID]; found && j != i {
+				parent, ok = j, true
+				if ref.RefType == "CHILD_OF" {
+					break
+				}
+			}
+		}
+		if ok {
+			children[parent] = append(children[parent], i)
+		} else {
+			roots = append(roots, i)
+		}
+	}
+
+	g := spanGraph{
+		roots:  groupConcurrent(trace.Spans, roots),
+		stages: make([][][]int, len(trace.Spans)),
+	}
+	for i := range children {
+		g.stages[i] = groupConcurrent(trace.Spans, children[i])
+	}
+	return g
+}
+
+// groupConcurrent sorts siblings by start time and splits them into stages:
+// a span joins the current stage if it starts before every span so far in
+// the stage has ended.
+func groupConcurrent(spans []common.Span, siblings []int) [][]int {
+	sort.SliceStable(siblings, func(a, b int) bool {
+		return spans[siblings[a]].StartTime < spans[siblings[b]].StartTime
+	})
+	var stages [][]int
+	var stageEnd int64
+	for _, i := range siblings {
+		span := spans[i]
+		if len(stages) == 0 || span.StartTime >= stageEnd {
+			stages = append(stages, nil)
+			stageEnd = 0
+		}
+		stages[len(stages)-1] = append(stages[len(stages)-1], i)
+		if end := span.StartTime + span.Duration; end > stageEnd {
+			stageEnd = end
+		}
+	}
+	return stages
+}
+
+// criticalPath returns the predicted duration of the trace when span i alone
+// takes own[i]: every span waits for its stages of children one after
+// another, and each stage for its slowest span. Spans that cannot be reached
+// from a root (a reference cycle) are ignored.
+func (g spanGraph) criticalPath(own []float64) float64 {
+	var cost func(i int) float64
+	cost = func(i int) float64 {
+		return own[i] + g.stagesCost(g.stages[i], cost)
+	}
+	return g.stagesCost(g.roots, cost)
+}
+
+func (g spanGraph) stagesCost(stages [][]int, cost func(int) float64) float64 {
+	total := 0.0
+	for _, stage := range stages {
+		slowest := 0.0
+		for _, i := range stage {
+			if c := cost(i); c > slowest {
+				slowest = c
+			}
+		}
+		total += slowest
+	}
+	return total
+}
+
+// spanGraphOf returns the span graph of the i-th trace of p.
+func (p *Problem) spanGraphOf(i int) spanGraph {
+	if p.spanGraphs != nil {
+		return p.spanGraphs[i]
+	}
+	return newSpanGraph(p.Traces.Data[i])
+}
@@ -0,0 +1,60 @@
+package algorithms
+
+import (
+	"math"
+	"optimizer/common"
+	"testing"
+)
+
+func testSpan(id, parent string, start, duration int64) common.Span {
+	span := common.Span{SpanID: id, StartTime: start, Duration: duration}
+	if parent != "" {
+		span.References = append(span.References, struct {
+			RefType string `json:"refType"`
+			SpanID  string `json:"spanID"`
+		}{"CHILD_OF", parent})
+	}
+	return span
+}
+
+func TestCriticalPath(t *testing.T) {
+	// a calls b and c in parallel, then d; c calls e
+	trace := common.Trace{Spans: []common.Span{
+		testSpan("d", "a", 70, 20),
+		testSpan("a", "", 0, 100),
+		testSpan("c", "a", 20, 40),
+		testSpan("b", "a", 10, 40),
+		testSpan("e", "c", 25, 10),
+	}}
+	g := newSpanGraph(trace)
+	own := []float64{5, 1, 2, 4, 3} // d, a, c, b, e
+	// a + max(b, c+e) + d
+	if got, want := g.criticalPath(own), 1+max(4.0, 2+3)+5; got != want {
+		t.Errorf("criticalPath = %v, want %v", got, want)
+	}
+}
+
+func TestPredictLatencyCriticalPath(t *testing.T) {
+	problem := fanOutProblem()
+	solution := problem.NewSolution()
+	for _, service := range problem.Topology.Services {
+		solution["edge1"][service] = 1
+	}
+	// µs of frontend, cartservice and currencyservice, the callees each
+	// with a 50 ms hop; queueing adds well under 0.1 µs
+	own := []float64{1000, 2000 + 50000, 3000 + 50000}
+
+	critical := problem.predictDurations(solution)[0]
+	if want := own[0] + max(own[1], own[2]); math.Abs(critical-want) > 0.1 {
+		t.Errorf("critical path = %f µs, want %f", critical, want)
+	}
+
+	problem.spanGraphs = []spanGraph{{roots: [][]int{{0}, {1}, {2}}, stages: make([][][]int, 3)}}
+	summed := problem.predictDurations(solution)[0]
+	if want := own[0] + own[1] + own[2]; math.Abs(summed-want) > 0.1 {
+		t.Errorf("sum over spans = %f µs, want %f", summed, want)
+	}
+	if critical >= summed {
+		t.Errorf("critical path %f µs is not shorter than the sum over spans %f µs", critical, summed)
+	}
+}
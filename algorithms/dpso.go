@@ -1,82 +1,54 @@
 package algorithms
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
+	"math"
 	"math/rand"
-	"optimizer/analyzer"
 	"optimizer/common"
 	"os"
 	"strconv"
-	"time"
 )
 
 type DPSO struct {
+	Problem      *Problem
 	Particles    []common.Particle
 	BestSolution map[string]map[string]int
 	BestScore    float64
 	NumParticles int
 	MaxIter      int
+	HistoryFile  string     // CSV file the per-iteration best score is appended to, if set
+	History      []float64  // best score after each iteration
+	Rand         *rand.Rand // source of all randomness of the run
+	Workers      int        // particles evaluated concurrently
 }
 
-var heatmap map[common.CallKey]float64
-var traceData common.TraceData
-var serviceConstraints common.ResourceConstraints
-var nodeConstraints common.NodeConstraints
-
-var processTimeMap map[string]map[string]int64
-var processTimeCloudMap map[string]map[string]int64
-
-func Init() {
-	common.LoadJSONFile("app.json", &traceData)
-	common.LoadJSONFile("resources_services.json", &serviceConstraints)
-	common.LoadJSONFile("resources_nodes.json", &nodeConstraints)
-
-	common.LoadJSONFile("processing_time_edge.json", &processTimeMap)
-	common.LoadJSONFile("processing_time_cloud.json", &processTimeCloudMap)
-
-	// callCounts = CountServiceCalls(traceData)
-	heatmap, _ = analyzer.LoadDepICsFromCSV("depICs.csv")
-}
-
-func NewDPSO(numParticles, maxIter int) *DPSO {
-	rand.Seed(time.Now().UnixNano())
+// NewDPSO builds a swarm of random particles; Optimize scores them with the
+// swarm's Workers.
+func NewDPSO(problem *Problem, numParticles, maxIter int, rng *rand.Rand) *DPSO {
 	particles := make([]common.Particle, numParticles)
-	bestSolution := make(map[string]map[string]int)
-	for _, node := range common.Nodes {
-		bestSolution[node] = make(map[string]int)
-		for _, service := range common.Services {
-			bestSolution[node][service] = 0
-		}
-	}
-	bestScore := -1.0
-
 	for i := range particles {
 		particles[i] = common.Particle{
-			Solution:     randomSolution(),
-			Velocity:     makeVelocity(),
-			BestSolution: make(map[string]map[string]int),
-			BestScore:    -1.0,
-		}
-		// Initialize BestSolution maps
-		for _, node := range common.Nodes {
-			particles[i].BestSolution[node] = make(map[string]int)
-		}
-		common.CopySolution(particles[i].BestSolution, particles[i].Solution)
-		score := evaluate(particles[i].Solution)
-		particles[i].BestScore = score
-		if score > bestScore {
-			bestScore = score
-			common.CopySolution(bestSolution, particles[i].Solution)
+			Solution:     problem.randomSolution(rng),
+			Velocity:     problem.makeVelocity(),
+			BestSolution: problem.NewSolution(),
+			BestScore:    math.Inf(1), // scored by scoreNew
 		}
 	}
+	bestSolution := problem.NewSolution()
+	bestScore := math.Inf(1)
 
 	return &DPSO{
+		Problem:      problem,
 		Particles:    particles,
 		BestSolution: bestSolution,
 		BestScore:    bestScore,
 		NumParticles: numParticles,
 		MaxIter:      maxIter,
+		HistoryFile:  "dpso_optimization_results.csv",
+		Rand:         rng,
+		Workers:      defaultWorkers(),
 	}
 }
 
@@ -90,60 +62,94 @@ func writeCSVHeader(filePath string) error {
 		}
 		defer f.Close()
 		writer := csv.NewWriter(f)
-		defer writer.Flush()
-		return writer.Write([]string{"Iteration", "BestScore"})
+		if err := writer.Write([]string{"Iteration", "BestScore"}); err != nil {
+			return err
+		}
+		writer.Flush()
+		return writer.Error()
 	}
 	return nil
 }
 
-func (dpso *DPSO) Optimize() {
+// Optimize runs up to MaxIter iterations, recording the best score after
+// each in History. It returns early, keeping the best solution found so far,
+// once ctx is done, and fails if a deployment cannot be scored or the
+// history cannot be written.
+func (dpso *DPSO) Optimize(ctx context.Context) error {
 	w, c1, c2 := 0.5, 1.5, 1.5
-	csvFileName := "dpso_optimization_results.csv" // Define your CSV file name
 
-	if err := writeCSVHeader(csvFileName); err != nil {
-		fmt.Printf("Error writing CSV header: %v\n", err)
-		return // Or handle error as appropriate
-	}
-	// Open the CSV file in append mode. If it doesn't exist, it will be created.
-	f, err := os.OpenFile(csvFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		fmt.Printf("Error opening CSV file: %v\n", err)
-		return // Or handle error as appropriate
+	var writer *csv.Writer
+	if csvFileName := dpso.HistoryFile; csvFileName != "" {
+		if err := writeCSVHeader(csvFileName); err != nil {
+			return fmt.Errorf("writing %s: %w", csvFileName, err)
+		}
+		// Open the CSV file in append mode. If it doesn't exist, it will be created.
+		f, err := os.OpenFile(csvFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", csvFileName, err)
+		}
+		defer f.Close() // Ensure the file is closed when the function exits
+
+		writer = csv.NewWriter(f)
+		defer writer.Flush() // Ensure all buffered data is written to the file
 	}
-	defer f.Close() // Ensure the file is closed when the function exits
 
-	writer := csv.NewWriter(f)
-	defer writer.Flush() // Ensure all buffered data is written to the file
+	if err := dpso.Problem.scoreNew(ctx, dpso.Particles, dpso.Workers); err != nil {
+		return err
+	}
+	for _, p := range dpso.Particles {
+		if p.BestScore < dpso.BestScore {
+			dpso.BestScore = p.BestScore
+			common.CopySolution(dpso.BestSolution, p.BestSolution)
+		}
+	}
 
+	topology := dpso.Problem.Topology
+	nodes := topology.NodeNames()
+	solutions := make([]map[string]map[string]int, len(dpso.Particles))
 	for iter := 0; iter < dpso.MaxIter; iter++ {
-		fmt.Printf("\nIteration %d start!!!\n", iter)
+		// Move every particle first; the moves draw from dpso.Rand in a fixed
+		// order, so they stay sequential.
 		for i := range dpso.Particles {
 			p := &dpso.Particles[i]
-			for _, node := range common.Nodes {
-				for _, service := range common.Services {
-					r1, r2 := rand.Float64(), rand.Float64()
+			for _, node := range nodes {
+				for _, service := range topology.Services {
+					r1, r2 := dpso.Rand.Float64(), dpso.Rand.Float64()
 					p.Velocity[node][service] = w*p.Velocity[node][service] +
 						c1*r1*float64(p.BestSolution[node][service]-p.Solution[node][service]) +
 						c2*r2*float64(dpso.BestSolution[node][service]-p.Solution[node][service])
-					threshold := sigmoid(p.Velocity[node][service])
+					threshold := sigmoid(p.Velocity[node][service], dpso.Rand)
 
 					// fmt.Printf("threshold = %f\n", threshold)
 
 					p.Solution[node][service] = 0
-					if rand.Float64() < threshold {
+					if dpso.Rand.Float64() < threshold {
 						p.Solution[node][service]++
 					}
-					if rand.Float64() < threshold {
+					if dpso.Rand.Float64() < threshold {
 						p.Solution[node][service]++
 					}
-					if rand.Float64() < threshold {
+					if dpso.Rand.Float64() < threshold {
 						p.Solution[node][service]++
 					}
 				}
 			}
+			dpso.Problem.repairIfEnabled(p.Solution)
+
+			solutions[i] = p.Solution
+		}
+
+		scores, err := dpso.Problem.evaluateAll(ctx, solutions, dpso.Workers)
+		if stoppedBy(ctx, err) {
+			fmt.Printf("Stopped before iteration %d finished: %v\n", iter, err)
+			return nil
+		} else if err != nil {
+			return err
+		}
 
+		for i, score := range scores {
+			p := &dpso.Particles[i]
 			// small is better (faster)
-			score := evaluate(p.Solution)
 			if score < p.BestScore {
 				p.BestScore = score
 				common.CopySolution(p.BestSolution, p.Solution)
@@ -153,99 +159,67 @@ func (dpso *DPSO) Optimize() {
 				common.CopySolution(dpso.BestSolution, p.Solution)
 			}
 		}
-		fmt.Printf("Iteration %d: Best Score = %f, BestSolution till this iteration be like: \n", iter, dpso.BestScore)
-		// TODO: save iter, dpso.BestScore to a csv file
-		record := []string{strconv.Itoa(iter), strconv.FormatFloat(dpso.BestScore, 'f', -1, 64)}
-		if err := writer.Write(record); err != nil {
-			fmt.Printf("Error writing record to CSV: %v\n", err)
-			// Decide how to handle this error: continue, break, return
-		}
-		writer.Flush() // Flush after each write to ensure data is written immediately
-		if iter == dpso.MaxIter-1 {
-			common.PrintJSON(dpso.BestSolution, "")
+		dpso.History = append(dpso.History, dpso.BestScore)
+		if writer != nil {
+			record := []string{strconv.Itoa(iter), strconv.FormatFloat(dpso.BestScore, 'f', -1, 64)}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("writing %s: %w", dpso.HistoryFile, err)
+			}
+			writer.Flush() // Flush after each write to ensure data is written immediately
+			if err := writer.Error(); err != nil {
+				return fmt.Errorf("writing %s: %w", dpso.HistoryFile, err)
+			}
 		}
-		fmt.Println("-----------------------------------------")
 	}
+	return nil
 }
 
-func randomSolution() map[string]map[string]int {
-	solution := make(map[string]map[string]int)
-	for _, node := range common.Nodes {
-		solution[node] = make(map[string]int)
-	}
+// randomSolution places one replica of every service on a random node and
+// repairs the result in InfeasibleRepair mode. The solution lists every node
+// and service so that CopySolution overwrites all entries of its
+// destination.
+func (p *Problem) randomSolution(rng *rand.Rand) map[string]map[string]int {
+	nodes := p.Topology.NodeNames()
+	solution := p.NewSolution()
 
-	for _, service := range common.Services {
-		selectedNode := common.Nodes[rand.Intn(4)]
+	for _, service := range p.Topology.Services {
+		selectedNode := nodes[rng.Intn(len(nodes))]
 		solution[selectedNode][service] = 1
 	}
+	p.repairIfEnabled(solution)
 	return solution
 }
 
-func makeVelocity() map[string]map[string]float64 {
+func (p *Problem) makeVelocity() map[string]map[string]float64 {
 	velocity := make(map[string]map[string]float64)
-	for _, node := range common.Nodes {
+	for _, node := range p.Topology.NodeNames() {
 		velocity[node] = make(map[string]float64)
-		for _, service := range common.Services {
+		for _, service := range p.Topology.Services {
 			velocity[node][service] = 0.0
 		}
 	}
 	return velocity
 }
 
-func checkConstraints(solution map[string]map[string]int) bool {
-	// fmt.Println("enter checkConstraints()")
-	// common.PrintJSON(solution, "")
-	if solution["asus"]["frontend"] > 0 {
-		return false
-	}
-
-	for _, node := range common.Nodes {
-		for _, services := range solution {
-			totalCPU := 0
-			totalMemory := 0
-
-			// 計算該 VM 的資源使用量
-			for service, replicas := range services {
-				if constraint, exists := serviceConstraints[service]; exists {
-					totalCPU += constraint.CPU * replicas
-					totalMemory += constraint.Memory * replicas
-				} else {
-					fmt.Printf("Warning: Service %s not found in constraints\n", service)
-				}
-			}
-
-			// 取得該 VM 的資源限制
-			if nodeConstraint, exists := nodeConstraints[node]; exists {
-				if totalCPU > nodeConstraint.CPU {
-					// fmt.Printf("Node %s exceeds CPU limit: %d/%d\n", node, totalCPU, nodeConstraint.CPU)
-					return false
-				}
-				if totalMemory > nodeConstraint.Memory {
-					// fmt.Printf("Node %s exceeds Memory limit: %d/%d\n", node, totalMemory, nodeConstraint.Memory)
-					return false
-				}
-			} else {
-				fmt.Printf("Warning: Node %s not found in constraints\n", node)
-			}
-		}
-	}
-	return true
+// checkConstraints reports whether solution keeps every placement rule and
+// fits the capacity of every node.
+func (p *Problem) checkConstraints(solution map[string]map[string]int) bool {
+	return len(p.placementViolations(solution)) == 0 && len(p.Feasibility(solution)) == 0
 }
 
-func evaluate(solution map[string]map[string]int) float64 {
-	probC := CalculateProbability(solution, "asus")
-
-	// fmt.Printf("!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!")
-	// common.PrintJSON(probC, "")
-	if !checkConstraints(solution) {
-		return 999999999 // big number as penalty (means very slow)
+// Evaluate scores a deployment; smaller is better. Infeasible deployments
+// score after every feasible one, graded by how far they are from feasible
+// in InfeasibleGraded mode. It fails if the fitness of a feasible deployment
+// is negative, which happens when the DepIC heatmap outweighs the latency.
+func (p *Problem) Evaluate(solution map[string]map[string]int) (float64, error) {
+	if !p.checkConstraints(solution) {
+		return p.infeasibleScore(solution), nil // big number as penalty (means very slow)
 	}
 	// TODO: we should use fittness()
 	// 1. traceData: traces.json
 	// 2. deploymentConfig: solution
 	// 3. processTimeMap: process_time_edge.json
 	// 4. processTimeCloudMap: process_time_cloud.json
-	// 5. probC
 
 	// return 0.0
 
@@ -257,32 +231,13 @@ func evaluate(solution map[string]map[string]int) float64 {
 
 	// this is for depIC heatmap
 
-	var T = fitness(&traceData, solution, processTimeMap, processTimeCloudMap, probC, heatmap)
+	var T = p.fitness(solution)
 	if T < 0 {
-		fmt.Errorf("fitness() should not return negative value")
+		return 0, fmt.Errorf("negative fitness %f: the DepIC heatmap outweighs the latency", T)
 	}
-	return T
+	return feasibleScore(T), nil
 }
 
-func sigmoid(x float64) float64 {
-	return 1 / (1 + 1/float64(1+rand.ExpFloat64()))
-}
-
-func RunDPSO() {
-	start := time.Now()
-	Init()
-
-	fmt.Println("enter NewDPSO()")
-	dpso := NewDPSO(30, 100)
-	if dpso == nil {
-		fmt.Println("asdf")
-	}
-
-	fmt.Println("enter Optimize()!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!")
-	dpso.Optimize()
-
-	common.PrintJSON(dpso.BestSolution, "dpso_dep_solution_version2.json")
-	elapsed := time.Since(start) // 計算花費時間
-	fmt.Printf("execution time(dpso): %s\n\n", elapsed)
-	// UpDateDeploymentsByJSON("dpso_solution.json")
+func sigmoid(x float64, rng *rand.Rand) float64 {
+	return 1 / (1 + 1/float64(1+rng.ExpFloat64()))
 }
@@ -0,0 +1,130 @@
+package algorithms
+
+import (
+	"context"
+	"encoding/csv"
+	"math/rand"
+	"optimizer/common"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func testFiles() Files {
+	files := DefaultFiles()
+	for _, name := range []*string{&files.App, &files.ServiceResources, &files.NodeResources,
+		&files.EdgeTimes, &files.CloudTimes, &files.DepICs, &files.Network, &files.SLOs, &files.Cost, &files.Power} {
+		*name = filepath.Join("..", *name)
+	}
+	return files
+}
+
+func testTopology(t *testing.T) *common.Topology {
+	topo, err := common.LoadTopology("../topology.json")
+	if err != nil {
+		t.Fatalf("LoadTopology: %v", err)
+	}
+	return topo
+}
+
+func testProblem(t *testing.T) *Problem {
+	problem, err := LoadProblem(testFiles(), testTopology(t))
+	if err != nil {
+		t.Fatalf("LoadProblem: %v", err)
+	}
+	return problem
+}
+
+func testRand() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}
+
+func TestDPSO(t *testing.T) {
+	dpso := NewDPSO(testProblem(t), 5, 3, testRand())
+	dpso.HistoryFile = filepath.Join(t.TempDir(), "history.csv")
+	if err := dpso.Optimize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(dpso.HistoryFile)
+	if err != nil {
+		t.Fatalf("opening history: %v", err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("reading history: %v", err)
+	}
+	if len(records) != dpso.MaxIter+1 {
+		t.Fatalf("history has %d rows, want header plus %d", len(records), dpso.MaxIter)
+	}
+
+	prev := 0.0
+	for i, record := range records[1:] {
+		score, err := strconv.ParseFloat(record[1], 64)
+		if err != nil {
+			t.Fatalf("row %d: %v", i, err)
+		}
+		if i > 0 && score > prev {
+			t.Errorf("best score increased at iteration %d: %f -> %f", i, prev, score)
+		}
+		prev = score
+	}
+}
+
+func TestDPSOProblemsSideBySide(t *testing.T) {
+	full := testProblem(t)
+	small := testProblem(t)
+	small.Topology.Nodes = small.Topology.Nodes[1:] // drop vm1
+	small.index()
+
+	var wg sync.WaitGroup
+	runs := []*DPSO{NewDPSO(full, 4, 2, testRand()), NewDPSO(small, 4, 2, testRand())}
+	for _, dpso := range runs {
+		dpso.HistoryFile = filepath.Join(t.TempDir(), "history.csv")
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := dpso.Optimize(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, ok := runs[0].BestSolution["vm1"]; !ok {
+		t.Errorf("full topology solution misses vm1: %v", runs[0].BestSolution)
+	}
+	if _, ok := runs[1].BestSolution["vm1"]; ok {
+		t.Errorf("reduced topology solution places replicas on vm1: %v", runs[1].BestSolution)
+	}
+}
+
+func TestDPSOWorkersMatchSequential(t *testing.T) {
+	problem := testProblem(t)
+	var runs []*DPSO
+	for _, workers := range []int{1, 8} {
+		dpso := NewDPSO(problem, 8, 3, testRand())
+		dpso.HistoryFile = ""
+		dpso.Workers = workers
+		if err := dpso.Optimize(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, dpso)
+	}
+	if !reflect.DeepEqual(runs[0].History, runs[1].History) ||
+		!reflect.DeepEqual(runs[0].BestSolution, runs[1].BestSolution) {
+		t.Errorf("parallel run differs from sequential: %v vs %v", runs[1].History, runs[0].History)
+	}
+}
+
+func TestDPSOHistoryError(t *testing.T) {
+	dpso := NewDPSO(testProblem(t), 2, 1, testRand())
+	dpso.HistoryFile = filepath.Join(t.TempDir(), "missing", "history.csv")
+	if err := dpso.Optimize(context.Background()); err == nil {
+		t.Error("Optimize succeeded without a directory for its history")
+	}
+}
@@ -0,0 +1,30 @@
+package algorithms
+
+// energy returns the power in W drawn under solution by the nodes in
+// p.Power, which is the energy in Wh per hour: the idle draw of every node
+// hosting a replica plus its draw per core the replicas request. Nodes
+// without replicas are taken to be powered down, and nodes missing from the
+// profile draw nothing. It is 0 without a power profile.
+func (p *Problem) energy(solution map[string]map[string]int) float64 {
+	if p.Power == nil {
+		return 0
+	}
+
+	total := 0.0
+	for _, node := range p.Topology.Nodes {
+		power, ok := p.Power.PowerOf(node)
+		if !ok {
+			continue
+		}
+		millicores, replicas := 0, 0
+		for _, service := range p.Topology.Services {
+			millicores += solution[node.Name][service] * p.ServiceConstraints[service].CPU
+			replicas += solution[node.Name][service]
+		}
+		if replicas == 0 {
+			continue
+		}
+		total += power.Idle + power.PerCore*float64(millicores)/1000
+	}
+	return total
+}
@@ -0,0 +1,43 @@
+package algorithms
+
+import (
+	"math"
+	"optimizer/common"
+	"testing"
+)
+
+func TestEnergy(t *testing.T) {
+	problem := &Problem{
+		Topology: &common.Topology{
+			Nodes: []common.Node{
+				{Name: "edge1", Tier: common.TierEdge},
+				{Name: "edge2", Tier: common.TierEdge, Class: "pi"},
+				{Name: "cloud1", Tier: common.TierCloud},
+			},
+			Services: []string{"a", "b"},
+		},
+		ServiceConstraints: common.ResourceConstraints{
+			"a": {CPU: 500},
+			"b": {CPU: 250},
+		},
+		Power: &common.PowerProfile{Nodes: map[string]common.Power{
+			"edge": {Idle: 10, PerCore: 8},
+			"pi":   {Idle: 2, PerCore: 4},
+		}},
+	}
+
+	solution := problem.NewSolution()
+	solution["edge1"]["a"] = 2
+	solution["edge1"]["b"] = 1
+	solution["cloud1"]["b"] = 4 // not in the profile
+	want := 10 + 8*1.25         // edge2 hosts nothing and is off
+	if got := problem.energy(solution); math.Abs(got-want) > 1e-9 {
+		t.Errorf("energy = %v, want %v", got, want)
+	}
+
+	solution["edge2"]["b"] = 2
+	want += 2 + 4*0.5
+	if got := problem.energy(solution); math.Abs(got-want) > 1e-9 {
+		t.Errorf("with edge2: energy = %v, want %v", got, want)
+	}
+}
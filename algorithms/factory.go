@@ -0,0 +1,198 @@
+package algorithms
+
+import (
+	"optimizer/common"
+	"sort"
+	"sync"
+)
+
+// Factory merges the Pareto fronts of the PSO and GWO swarms of a PS-GWCA run
+// and decides when one swarm should be transformed into the other.
+type Factory struct {
+	SharedMem *common.SharedMemory
+	MaxIter   int
+	History   []float64 // best score of the merged front after each iteration
+	// Contributed counts the members of the merged front each swarm
+	// contributed after each iteration, keyed by Source.
+	Contributed []map[string]int
+}
+
+func NewFactory(sharedMem *common.SharedMemory, maxIter int) *Factory {
+	return &Factory{
+		SharedMem: sharedMem,
+		MaxIter:   maxIter,
+	}
+}
+
+func (f *Factory) Run(abDone *sync.WaitGroup, aDone, bDone <-chan struct{}, done chan<- struct{}, nextIter chan struct{}) {
+	count := 0
+
+	for i := 0; i < f.MaxIter; i++ {
+		// Wait for both critical sections A and B to complete
+		abDone.Wait()
+		// Consume signals from A and B
+		<-aDone
+		<-bDone
+
+		f.SharedMem.Lock()
+		psoFront := f.SharedMem.PSOFront
+		gwoFront := f.SharedMem.GWOFront
+		f.SharedMem.Unlock()
+
+		// Merge and reorder Pareto fronts
+		var candidates []common.Particle
+		candidates = append(candidates, psoFront...)
+		candidates = append(candidates, gwoFront...)
+		var mergedFront []common.Particle
+		if fronts := nonDominatedSort(candidates); len(fronts) > 0 {
+			mergedFront = fronts[0]
+		}
+		sort.SliceStable(mergedFront, func(a, b int) bool {
+			return mergedFront[a].BestScore < mergedFront[b].BestScore
+		})
+
+		// Evaluate algorithm performance: the swarm that contributes less
+		// to the merged front drifts towards being transformed.
+		contributed := make(map[string]int)
+		for _, p := range mergedFront {
+			contributed[p.Source]++
+		}
+		f.Contributed = append(f.Contributed, contributed)
+		if f.SharedMem.Transform == 0 {
+			if contributed[SourceGWO] > contributed[SourcePSO] {
+				count++
+			} else if contributed[SourcePSO] > contributed[SourceGWO] {
+				count--
+			}
+		}
+
+		// Check for transformation
+		if count > f.MaxIter/2 {
+			f.SharedMem.Lock()
+			f.SharedMem.Transform = 1 // PSO to GWO
+			f.SharedMem.Unlock()
+		} else if count < -f.MaxIter/2 {
+			f.SharedMem.Lock()
+			f.SharedMem.Transform = 2 // GWO to PSO
+			f.SharedMem.Unlock()
+		}
+
+		// Update shared memory with merged Pareto front
+		f.SharedMem.Lock()
+		f.SharedMem.MergedFront = mergedFront
+		f.SharedMem.Unlock()
+		if len(mergedFront) > 0 {
+			f.History = append(f.History, mergedFront[0].BestScore)
+		}
+
+		// Signal completion of this iteration
+		done <- struct{}{}
+		// Wait for the next iteration signal
+		if _, ok := <-nextIter; !ok {
+			break
+		}
+
+	}
+}
+
+// updateParetoFront adds candidate to front unless a member dominates it or
+// has the same objectives, and drops the members candidate dominates.
+func updateParetoFront(front []common.Particle, candidate common.Particle) []common.Particle {
+	var kept []common.Particle
+	for _, member := range front {
+		if dominates(member, candidate) || sameObjectives(member, candidate) {
+			return front
+		}
+		if !dominates(candidate, member) {
+			kept = append(kept, member)
+		}
+	}
+	return append(kept, candidate)
+}
+
+// dominates reports whether p1 Pareto-dominates p2. A feasible solution
+// dominates every infeasible one.
+func dominates(p1, p2 common.Particle) bool {
+	if p1.Feasible != p2.Feasible {
+		return p1.Feasible
+	}
+	better := false
+	for i := range p1.Objectives {
+		if p1.Objectives[i] > p2.Objectives[i] {
+			return false
+		}
+		if p1.Objectives[i] < p2.Objectives[i] {
+			better = true
+		}
+	}
+	return better
+}
+
+func sameObjectives(p1, p2 common.Particle) bool {
+	if p1.Feasible != p2.Feasible || len(p1.Objectives) != len(p2.Objectives) {
+		return false
+	}
+	for i := range p1.Objectives {
+		if p1.Objectives[i] != p2.Objectives[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// nonDominatedSort splits particles into fronts of increasing rank (fast
+// non-dominated sorting): fronts[0] is the Pareto front, fronts[1] is the
+// front once fronts[0] is removed, and so on. Duplicates of a solution's
+// objectives are kept only once.
+func nonDominatedSort(particles []common.Particle) [][]common.Particle {
+	var unique []common.Particle
+	for _, p := range particles {
+		duplicate := false
+		for _, u := range unique {
+			if sameObjectives(p, u) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			unique = append(unique, p)
+		}
+	}
+
+	dominatedBy := make([]int, len(unique))  // number of particles dominating i
+	dominating := make([][]int, len(unique)) // particles i dominates
+	var current []int
+	for i := range unique {
+		for j := range unique {
+			if i == j {
+				continue
+			}
+			if dominates(unique[i], unique[j]) {
+				dominating[i] = append(dominating[i], j)
+			} else if dominates(unique[j], unique[i]) {
+				dominatedBy[i]++
+			}
+		}
+		if dominatedBy[i] == 0 {
+			current = append(current, i)
+		}
+	}
+
+	var fronts [][]common.Particle
+	for len(current) > 0 {
+		front := make([]common.Particle, 0, len(current))
+		var next []int
+		for _, i := range current {
+			front = append(front, unique[i])
+			for _, j := range dominating[i] {
+				dominatedBy[j]--
+				if dominatedBy[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		fronts = append(fronts, front)
+		current = next
+	}
+	return fronts
+}
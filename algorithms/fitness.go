@@ -1,12 +1,15 @@
 package algorithms
 
 import (
-	"fmt"
 	"log"
+	"math"
 	"optimizer/common"
+	"sort"
 )
 
-func CalculateProbability(deploymentConfig map[string]map[string]int, targetHost string) map[string]float64 {
+// CalculateProbability returns, for each service, the share of its replicas
+// that run on the target hosts.
+func CalculateProbability(deploymentConfig map[string]map[string]int, targetHosts ...string) map[string]float64 {
 	// Initialize map to store total amounts of each service
 	totalServiceAmounts := make(map[string]int)
 
@@ -20,16 +23,20 @@ func CalculateProbability(deploymentConfig map[string]map[string]int, targetHost
 	// Initialize map to store ratios
 	ratios := make(map[string]float64)
 
-	// Check if target host exists
-	if _, ok := deploymentConfig[targetHost]; !ok {
-		log.Printf("Target host '%s' not found in deployment config.\n", targetHost)
-		return ratios
-
+	// Check if target hosts exist
+	for _, targetHost := range targetHosts {
+		if _, ok := deploymentConfig[targetHost]; !ok {
+			log.Printf("Target host '%s' not found in deployment config.\n", targetHost)
+			return ratios
+		}
 	}
 
-	// Calculate ratios for each service on the target host
+	// Calculate ratios for each service on the target hosts
 	for service, totalAmount := range totalServiceAmounts {
-		targetAmount := deploymentConfig[targetHost][service]
+		targetAmount := 0
+		for _, targetHost := range targetHosts {
+			targetAmount += deploymentConfig[targetHost][service]
+		}
 		if totalAmount > 0 {
 			ratios[service] = float64(targetAmount) / float64(totalAmount)
 		} else {
@@ -39,57 +46,170 @@ func CalculateProbability(deploymentConfig map[string]map[string]int, targetHost
 	return ratios
 }
 
-// should input 1. app(TraceData) 2. deploymentConfig, 3. processing time 4. processing time on cloud
-func fitness(traceData *common.TraceData, deploymentConfig map[string]map[string]int,
-	processTimeMap map[string]map[string]int64, processTimeCloudMap map[string]map[string]int64,
-	probC map[string]float64, heatmap map[common.CallKey]float64) float64 {
+// fitness predicts the traces of p under deploymentConfig, rewards
+// co-locating services with a high DepIC and charges p.SpendWeight per unit
+// of spend per hour and p.EnergyWeight per Wh per hour.
+func (p *Problem) fitness(deploymentConfig map[string]map[string]int) float64 {
+	score := p.predictLatency(deploymentConfig) - p.colocationScore(deploymentConfig)
+	if p.SpendWeight != 0 {
+		score += p.SpendWeight * p.spend(deploymentConfig)
+	}
+	if p.EnergyWeight != 0 {
+		score += p.EnergyWeight * p.energy(deploymentConfig)
+	}
+	return score
+}
+
+// predictLatency returns the latency objective p.Latency of the traces
+// predicted under deploymentConfig. It only reads p, so it is safe to call
+// from several goroutines.
+func (p *Problem) predictLatency(deploymentConfig map[string]map[string]int) float64 {
+	return p.latency(p.predictDurations(deploymentConfig))
+}
+
+// predictDurations returns the predicted duration in µs of each trace of p,
+// taking its critical path through its spans. A span's processing time is
+// that of the nodes its service runs on, weighted by their share of its
+// replicas.
+func (p *Problem) predictDurations(deploymentConfig map[string]map[string]int) []float64 {
+	traceData := &p.Traces
+	nodes := p.Topology.NodeNames()
+	times := p.allNodeTimes()
+	ratios := p.nodeRatios(deploymentConfig)
+	waits := p.queueWaits(deploymentConfig)
+
+	durations := make([]float64, len(traceData.Data))
 	for i := range traceData.Data {
-		var predictDuration float64 = 0
+		spans := traceData.Data[i].Spans
+		own := make([]float64, len(spans)) // predicted time of each span without its children
 
 		// add response time
-		for _, span := range traceData.Data[i].Spans {
-			var processTimeOnEdge int64
-			var processTimeOnCloud int64
-			var ok bool
-
-			if processTimeOnEdge, ok = processTimeMap[span.ServiceName][span.OperationName]; ok {
-			} else {
-				fmt.Println("Error: No response time found for operation ", span.OperationName, " in process ", span.ServiceName)
+		for j, span := range spans {
+			own[j] = p.processTime(times, ratios, span.ServiceName, span.OperationName)
+			// calls entering the service queue for a free replica
+			if span.ParentService != span.ServiceName {
+				own[j] += waits[span.ServiceName]
 			}
+		}
 
-			if processTimeOnEdge, ok = processTimeCloudMap[span.ServiceName][span.OperationName]; ok {
-			} else {
-				fmt.Println("Error: No response time found for operation ", span.OperationName, " in process ", span.ServiceName)
+		// add network delay
+		if p.hasNetworkModel() {
+			for j, span := range spans {
+				own[j] += p.hopDelay(nodes, ratios, span.ParentService, span.ServiceName) * 1000
+			}
+		} else {
+			var networkDelay float64 = defaultHopDelay * 1000 // 50 ms
+			for j, span := range spans {
+				if span.ServiceName != p.Topology.Entrypoint {
+					own[j] += networkDelay
+				}
 			}
-
-			// add response time (edge or cloud)
-			predictDuration += probC[span.ServiceName]*float64(processTimeOnCloud) + (1-probC[span.ServiceName])*float64(processTimeOnEdge)
 		}
 
-		// TODO: might be more accurate
-		var networkDelay float64 = 50 * 1000 // 50 ms
-		for _, span := range traceData.Data[i].Spans {
-			if span.ServiceName != "frontend" {
-				predictDuration += networkDelay
+		// concurrent calls overlap, so the trace takes as long as its critical path
+		durations[i] = p.spanGraphOf(i).criticalPath(own)
+	}
+	return durations
+}
+
+// defaultHopDelay is the network time in ms of a call between two nodes
+// without a known link.
+const defaultHopDelay = 50.0
+
+func (p *Problem) hasNetworkModel() bool {
+	return p.Network != nil && len(p.Network.Stats) > 0
+}
+
+// nodeRatios returns, in p.Topology.NodeNames() order, the share of each
+// service's replicas placed on every node.
+func (p *Problem) nodeRatios(deploymentConfig map[string]map[string]int) []map[string]float64 {
+	nodes := p.Topology.NodeNames()
+	ratios := make([]map[string]float64, len(nodes))
+	for i, node := range nodes {
+		ratios[i] = CalculateProbability(deploymentConfig, node)
+	}
+	return ratios
+}
+
+// hopDelay returns the expected network time in ms of a call from caller to
+// callee: the transfer time of the call's payload over every pair of nodes,
+// weighted by the probability that the replicas serving the call run there.
+// Calls within a service, on one node without a listed link, or from no
+// caller cost nothing; pairs without a known link cost defaultHopDelay.
+func (p *Problem) hopDelay(nodes []string, ratios []map[string]float64, caller, callee string) float64 {
+	if caller == "" || caller == "none" || caller == callee {
+		return 0
+	}
+	bytes := p.payloadBytes(caller, callee)
+
+	expected, placed := 0.0, 0.0
+	for a := range nodes {
+		for b := range nodes {
+			weight := ratios[a][caller] * ratios[b][callee]
+			if weight == 0 {
+				continue
+			}
+			placed += weight
+			if stats, ok := p.Network.GetStats(nodes[a], nodes[b]); ok {
+				expected += weight * stats.TransferTime(bytes)
+			} else if a != b {
+				expected += weight * defaultHopDelay
 			}
 		}
+	}
+	// a service without replicas has no placement; assume an unknown link
+	return expected + math.Max(0, 1-placed)*defaultHopDelay
+}
 
-		// Finally
-		traceData.Data[i].PredictedDuration = int64(predictDuration)
+// payloadBytes returns the bytes sent by one call from caller to callee.
+func (p *Problem) payloadBytes(caller, callee string) int {
+	if bytes, ok := p.Payloads[common.CallKey{From: caller, To: callee}]; ok {
+		return bytes
 	}
-	common.CalculateAverageDuration(traceData)
+	return p.DefaultPayload
+}
 
+// colocationScore sums the DepIC of every service pair weighted by the
+// probability that both land on the same node.
+func (p *Problem) colocationScore(deploymentConfig map[string]map[string]int) float64 {
 	// TODO: leverage the heatmap part
+	if len(p.Heatmap) == 0 {
+		return 0
+	}
+	ratios := p.nodeRatios(deploymentConfig)
+
 	heatmapScore := 0.0
-	for k, v := range heatmap {
+	for _, k := range p.callKeys() { // fixed order keeps the float sum reproducible
+		v := p.Heatmap[k]
 		// fmt.Printf("%s -> %s: %d times\n", k.From, k.To, v)
 		prob := 0.0
-		for _, node := range common.Nodes {
-			r := CalculateProbability(deploymentConfig, node)
+		for _, r := range ratios {
 			prob += r[k.From] * r[k.To]
 		}
 		heatmapScore += prob * float64(v)
 	}
 
-	return float64(traceData.AveragePredictedDuration) - heatmapScore
+	return heatmapScore
+}
+
+// callKeys returns the keys of p.Heatmap in sorted order.
+func (p *Problem) callKeys() []common.CallKey {
+	if p.heatmapKeys != nil {
+		return p.heatmapKeys
+	}
+	return sortedCallKeys(p.Heatmap)
+}
+
+func sortedCallKeys(heatmap map[common.CallKey]float64) []common.CallKey {
+	keys := make([]common.CallKey, 0, len(heatmap))
+	for k := range heatmap {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].From != keys[j].From {
+			return keys[i].From < keys[j].From
+		}
+		return keys[i].To < keys[j].To
+	})
+	return keys
 }
@@ -0,0 +1,95 @@
+package algorithms
+
+import (
+	"math"
+	"optimizer/common"
+	"testing"
+)
+
+// fanOutProblem returns a problem with one trace in which frontend calls
+// cartservice and currencyservice in parallel. The trace spans 1000 s, so
+// queueing adds next to nothing.
+func fanOutProblem() *Problem {
+	span := func(id, parent, service, parentService string, start, duration int64) common.Span {
+		s := testSpan(id, parent, start, duration)
+		s.ServiceName, s.OperationName, s.ParentService = service, "op", parentService
+		return s
+	}
+	problem := &Problem{
+		Topology: &common.Topology{
+			Nodes:      []common.Node{{Name: "edge1", Tier: common.TierEdge}, {Name: "cloud1", Tier: common.TierCloud}},
+			Services:   []string{"frontend", "cartservice", "currencyservice"},
+			Entrypoint: "frontend",
+		},
+		Traces: common.TraceData{Data: []common.Trace{{Spans: []common.Span{
+			span("a", "", "frontend", "", 1, 1e9),
+			span("b", "a", "cartservice", "frontend", 10, 40),
+			span("c", "a", "currencyservice", "frontend", 20, 40),
+		}}}},
+		ProcessTimeEdge: map[string]map[string]int64{
+			"frontend":        {"op": 1000},
+			"cartservice":     {"op": 2000},
+			"currencyservice": {"op": 3000},
+		},
+		ProcessTimeCloud: map[string]map[string]int64{
+			"frontend":        {"op": 1000},
+			"cartservice":     {"op": 500},
+			"currencyservice": {"op": 1000},
+		},
+		Network: common.NewLatencyBandwidthMap(), // flat 50 ms per hop
+		Heatmap: map[common.CallKey]float64{{From: "frontend", To: "cartservice"}: 10},
+	}
+	problem.index()
+	return problem
+}
+
+func TestFitness(t *testing.T) {
+	problem := fanOutProblem()
+
+	// all on edge1: 1 ms + max(2, 3) ms + 50 ms per hop, less the DepIC of
+	// the co-located frontend and cartservice; the mean latency is truncated
+	// to whole ms, which also drops the few ns of queueing
+	solution := problem.NewSolution()
+	for _, service := range problem.Topology.Services {
+		solution["edge1"][service] = 1
+	}
+	if got, want := problem.fitness(solution), 1+3+50-10.0; math.Abs(got-want) > 1e-3 {
+		t.Errorf("all on edge1: fitness = %f, want %f", got, want)
+	}
+
+	// the callees on cloud1: 1 ms + max(0.5, 1) ms + 50 ms per hop
+	solution["edge1"]["cartservice"], solution["cloud1"]["cartservice"] = 0, 1
+	solution["edge1"]["currencyservice"], solution["cloud1"]["currencyservice"] = 0, 1
+	if got, want := problem.fitness(solution), 1+1+50.0; math.Abs(got-want) > 1e-3 {
+		t.Errorf("callees on cloud1: fitness = %f, want %f", got, want)
+	}
+}
+
+func TestHopDelay(t *testing.T) {
+	problem := testProblem(t)
+	if _, ok := problem.Network.GetStats("asus", "vm1"); !ok {
+		t.Fatalf("symmetric profile has no asus -> vm1 link")
+	}
+	nodes := problem.Topology.NodeNames()
+	solution := problem.NewSolution()
+	solution["vm1"]["frontend"] = 2
+	solution["vm1"]["cartservice"] = 1
+	solution["asus"]["cartservice"] = 1
+	ratios := problem.nodeRatios(solution)
+
+	// half of the calls stay on vm1, half cross the 40 ms / 100 Mbps link
+	// with the default 2048 byte payload
+	want := 0.5 * (40 + 2048*8/(100*1000.0))
+	if got := problem.hopDelay(nodes, ratios, "frontend", "cartservice"); math.Abs(got-want) > 1e-9 {
+		t.Errorf("frontend -> cartservice = %f ms, want %f", got, want)
+	}
+	if got := problem.hopDelay(nodes, ratios, "frontend", "frontend"); got != 0 {
+		t.Errorf("call within a service = %f ms, want 0", got)
+	}
+	if got := problem.hopDelay(nodes, ratios, "none", "frontend"); got != 0 {
+		t.Errorf("root span = %f ms, want 0", got)
+	}
+	if got := problem.hopDelay(nodes, ratios, "frontend", "emailservice"); got != defaultHopDelay {
+		t.Errorf("call to a service without replicas = %f ms, want %f", got, defaultHopDelay)
+	}
+}
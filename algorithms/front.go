@@ -0,0 +1,258 @@
+package algorithms
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"optimizer/common"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FrontMember is one trade-off of a Pareto front as it is exported.
+type FrontMember struct {
+	Source     string             `json:"source"`
+	Feasible   bool               `json:"feasible"`
+	Score      float64            `json:"score"` // scalar score of Problem.Evaluate
+	Objectives map[string]float64 `json:"objectives"`
+	Solution   Solution           `json:"solution"`
+}
+
+// NewFront converts the particles of a front for export.
+func NewFront(particles []common.Particle) []FrontMember {
+	front := make([]FrontMember, 0, len(particles))
+	for _, p := range particles {
+		objectives := make(map[string]float64, len(p.Objectives))
+		for i, value := range p.Objectives {
+			objectives[ObjectiveNames[i]] = value
+		}
+		front = append(front, FrontMember{
+			Source:     p.Source,
+			Feasible:   p.Feasible,
+			Score:      p.BestScore,
+			Objectives: objectives,
+			Solution:   p.Solution,
+		})
+	}
+	return front
+}
+
+// LoadFront reads a front written by WriteFrontJSON.
+func LoadFront(filename string) ([]FrontMember, error) {
+	var front []FrontMember
+	if err := common.LoadJSONFile(filename, &front); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", filename, err)
+	}
+	return front, nil
+}
+
+// WriteFrontJSON writes front to filename as a JSON array.
+func WriteFrontJSON(filename string, front []FrontMember) error {
+	data, err := json.MarshalIndent(front, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// WriteFrontCSV writes one row per member of front: source, feasibility,
+// score, every objective and the solution as compact JSON.
+func WriteFrontCSV(filename string, front []FrontMember) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	writer := csv.NewWriter(f)
+	header := append([]string{"index", "source", "feasible", "score"}, ObjectiveNames...)
+	if err := writer.Write(append(header, "solution")); err != nil {
+		return err
+	}
+	for i, m := range front {
+		solution, err := json.Marshal(m.Solution)
+		if err != nil {
+			return err
+		}
+		record := []string{strconv.Itoa(i), m.Source, strconv.FormatBool(m.Feasible),
+			strconv.FormatFloat(m.Score, 'f', -1, 64)}
+		for _, name := range ObjectiveNames {
+			record = append(record, strconv.FormatFloat(m.Objectives[name], 'f', -1, 64))
+		}
+		if err := writer.Write(append(record, string(solution))); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Selection describes how to pick one deployment from a front.
+type Selection struct {
+	By       string             // best, knee, weighted or bounded
+	Weights  map[string]float64 // weighted: weight per objective
+	Minimize string             // bounded: objective to minimize
+	Limits   map[string]float64 // bounded: upper bound per objective
+}
+
+// Pick returns the index of the member of front chosen by s. Only feasible
+// members are considered.
+func (s Selection) Pick(front []FrontMember) (int, error) {
+	switch s.By {
+	case "", "best":
+		return SelectBest(front)
+	case "knee":
+		return SelectKnee(front)
+	case "weighted":
+		return SelectWeighted(front, s.Weights)
+	case "bounded":
+		return SelectBounded(front, s.Minimize, s.Limits)
+	default:
+		return -1, fmt.Errorf("unknown selection %q (want best, knee, weighted or bounded)", s.By)
+	}
+}
+
+// SelectBest returns the feasible member with the lowest scalar score.
+func SelectBest(front []FrontMember) (int, error) {
+	return argmin(front, func(m FrontMember) float64 { return m.Score })
+}
+
+// SelectKnee returns the feasible member closest to the ideal point once
+// every objective is scaled to [0, 1] over the feasible members.
+func SelectKnee(front []FrontMember) (int, error) {
+	low, high := objectiveRanges(front)
+	return argmin(front, func(m FrontMember) float64 {
+		distance := 0.0
+		for _, name := range ObjectiveNames {
+			d := normalize(m.Objectives[name], low[name], high[name])
+			distance += d * d
+		}
+		return math.Sqrt(distance)
+	})
+}
+
+// SelectWeighted returns the feasible member with the lowest weighted sum of
+// its objectives, each scaled to [0, 1] over the feasible members so the
+// weights do not depend on the units.
+func SelectWeighted(front []FrontMember, weights map[string]float64) (int, error) {
+	if err := checkObjectives(weights); err != nil {
+		return -1, err
+	}
+	low, high := objectiveRanges(front)
+	return argmin(front, func(m FrontMember) float64 {
+		sum := 0.0
+		for name, weight := range weights {
+			sum += weight * normalize(m.Objectives[name], low[name], high[name])
+		}
+		return sum
+	})
+}
+
+// SelectBounded returns the feasible member with the lowest value of
+// objective among those within every limit, e.g. the lowest latency with
+// cost <= 0.5.
+func SelectBounded(front []FrontMember, objective string, limits map[string]float64) (int, error) {
+	if err := checkObjectives(map[string]float64{objective: 0}); err != nil {
+		return -1, err
+	}
+	if err := checkObjectives(limits); err != nil {
+		return -1, err
+	}
+	value := func(m FrontMember) float64 { return boundedValue(m, objective, limits) }
+	best, err := argmin(front, value)
+	if err == nil && math.IsInf(value(front[best]), 1) {
+		return -1, fmt.Errorf("no feasible member within the limits %v", limits)
+	}
+	return best, err
+}
+
+// boundedValue is the value of objective for m, or +Inf if m exceeds a limit.
+func boundedValue(m FrontMember, objective string, limits map[string]float64) float64 {
+	for name, limit := range limits {
+		if m.Objectives[name] > limit {
+			return math.Inf(1)
+		}
+	}
+	return m.Objectives[objective]
+}
+
+// ParseObjectiveValues parses "name=value,..." such as "latency=1,cost=0.5".
+func ParseObjectiveValues(s string) (map[string]float64, error) {
+	values := make(map[string]float64)
+	if s == "" {
+		return values, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("%q: want name=value", pair)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", pair, err)
+		}
+		values[name] = v
+	}
+	return values, checkObjectives(values)
+}
+
+func checkObjectives(values map[string]float64) error {
+	for name := range values {
+		known := false
+		for _, objective := range ObjectiveNames {
+			known = known || name == objective
+		}
+		if !known {
+			return fmt.Errorf("unknown objective %q (want one of %v)", name, ObjectiveNames)
+		}
+	}
+	return nil
+}
+
+// argmin returns the index of the feasible member with the lowest value.
+func argmin(front []FrontMember, value func(FrontMember) float64) (int, error) {
+	best, bestValue := -1, math.Inf(1)
+	for i, m := range front {
+		if !m.Feasible {
+			continue
+		}
+		if v := value(m); best == -1 || v < bestValue {
+			best, bestValue = i, v
+		}
+	}
+	if best == -1 {
+		return -1, fmt.Errorf("front has no feasible member")
+	}
+	return best, nil
+}
+
+// objectiveRanges returns the lowest and highest value of every objective
+// over the feasible members of front.
+func objectiveRanges(front []FrontMember) (low, high map[string]float64) {
+	low, high = make(map[string]float64), make(map[string]float64)
+	for _, name := range ObjectiveNames {
+		low[name], high[name] = math.Inf(1), math.Inf(-1)
+	}
+	for _, m := range front {
+		if !m.Feasible {
+			continue
+		}
+		for _, name := range ObjectiveNames {
+			low[name] = math.Min(low[name], m.Objectives[name])
+			high[name] = math.Max(high[name], m.Objectives[name])
+		}
+	}
+	return low, high
+}
+
+func normalize(value, low, high float64) float64 {
+	if high <= low {
+		return 0
+	}
+	return (value - low) / (high - low)
+}
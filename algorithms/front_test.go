@@ -0,0 +1,79 @@
+package algorithms
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func testFront() []FrontMember {
+	member := func(feasible bool, score, latency, cost float64) FrontMember {
+		return FrontMember{
+			Feasible:   feasible,
+			Score:      score,
+			Objectives: map[string]float64{"latency": latency, "cost": cost},
+		}
+	}
+	return []FrontMember{
+		member(true, 100, 100, 0.9),
+		member(true, 300, 300, 0.2),
+		member(true, 150, 150, 0.4),
+		member(false, 10, 10, 0.1),
+	}
+}
+
+func TestSelection(t *testing.T) {
+	tests := []struct {
+		selection Selection
+		want      int
+	}{
+		{Selection{By: "best"}, 0},
+		{Selection{By: "knee"}, 2},
+		{Selection{By: "weighted", Weights: map[string]float64{"cost": 1}}, 1},
+		{Selection{By: "bounded", Minimize: "latency", Limits: map[string]float64{"cost": 0.5}}, 2},
+	}
+	for _, tt := range tests {
+		got, err := tt.selection.Pick(testFront())
+		if err != nil {
+			t.Errorf("%s: %v", tt.selection.By, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: picked %d, want %d", tt.selection.By, got, tt.want)
+		}
+	}
+
+	if _, err := SelectBounded(testFront(), "latency", map[string]float64{"cost": 0.1}); err == nil {
+		t.Errorf("SelectBounded: want an error when no feasible member is within the limits")
+	}
+	if _, err := ParseObjectiveValues("latency=1,bogus=2"); err == nil {
+		t.Errorf("ParseObjectiveValues: want an error for an unknown objective")
+	}
+}
+
+func TestFrontRoundTrip(t *testing.T) {
+	problem := testProblem(t)
+	ps := NewPSGWCA(problem, 6, 2, testRand())
+	ps.Optimize(context.Background())
+	front := NewFront(ps.SharedMem.MergedFront)
+
+	filename := filepath.Join(t.TempDir(), "front.json")
+	if err := WriteFrontJSON(filename, front); err != nil {
+		t.Fatalf("WriteFrontJSON: %v", err)
+	}
+	loaded, err := LoadFront(filename)
+	if err != nil {
+		t.Fatalf("LoadFront: %v", err)
+	}
+	if len(loaded) != len(front) {
+		t.Fatalf("loaded %d members, want %d", len(loaded), len(front))
+	}
+	for i := range front {
+		if loaded[i].Source != front[i].Source || len(loaded[i].Objectives) != NumObjectives {
+			t.Errorf("member %d: got %+v", i, loaded[i])
+		}
+	}
+	if err := WriteFrontCSV(filepath.Join(t.TempDir(), "front.csv"), front); err != nil {
+		t.Fatalf("WriteFrontCSV: %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"optimizer/common"
+	"sync"
+)
+
+// GWO represents the GWO algorithm state
+type GWO struct {
+	Problem      *Problem
+	SharedMem    *common.SharedMemory
+	Particles    []common.Particle
+	Alpha        common.Particle // Best solution
+	Beta         common.Particle // Second best
+	Delta        common.Particle // Third best
+	ParetoFront  []common.Particle
+	Front        *[]common.Particle // shared memory slot ParetoFront is published to
+	NumParticles int
+	MaxIter      int
+	History      []float64 // alpha score after each iteration of a standalone run
+	Rand         *rand.Rand
+	Workers      int // particles scored concurrently
+
+	// stop ends the PS-GWCA run this swarm is part of with the error that
+	// failed it
+	stop context.CancelCauseFunc
+}
+
+// NewGWO builds a pack of random particles; Optimize and Cooperate score
+// them with the pack's Workers.
+func NewGWO(problem *Problem, sharedMem *common.SharedMemory, numParticles, maxIter int, rng *rand.Rand) *GWO {
+	particles := make([]common.Particle, numParticles)
+	for i := range particles {
+		particles[i] = common.Particle{
+			Solution:     problem.randomSolutionForPS_GWCA(rng),
+			BestSolution: problem.NewSolution(),
+			BestScore:    math.Inf(1), // scored by scoreNew
+		}
+	}
+
+	// alpha, beta and delta are ranked once the particles are scored
+	var alpha, beta, delta common.Particle
+	alpha.BestScore = math.Inf(1)
+	beta.BestScore = math.Inf(1)
+	delta.BestScore = math.Inf(1)
+
+	return &GWO{
+		Problem:      problem,
+		SharedMem:    sharedMem,
+		Front:        &sharedMem.GWOFront,
+		Particles:    particles,
+		Alpha:        alpha,
+		Beta:         beta,
+		Delta:        delta,
+		NumParticles: numParticles,
+		MaxIter:      maxIter,
+		Rand:         rng,
+		Workers:      defaultWorkers(),
+	}
+}
+
+// Optimize runs the pack on its own for up to MaxIter iterations and then
+// collects its Pareto front in ParetoFront. It returns early, keeping the
+// best solution found so far in Alpha, once ctx is done.
+func (gwo *GWO) Optimize(ctx context.Context) error {
+	if err := gwo.scoreNew(ctx); err != nil {
+		return err
+	}
+	for i := 0; i < gwo.MaxIter && ctx.Err() == nil; i++ {
+		if err := gwo.iterate(ctx, i); err != nil {
+			return err
+		}
+		gwo.History = append(gwo.History, gwo.Alpha.BestScore)
+	}
+	gwo.updateFront()
+	return nil
+}
+
+// Cooperate runs the swarm as one half of a PS-GWCA hybrid: every iteration
+// it publishes its Pareto front, reseeds its worst particle from the merged
+// front and hands over to the factory through the channels.
+func (gwo *GWO) Cooperate(ctx context.Context, abDone *sync.WaitGroup, bDone chan<- struct{}, done chan<- struct{}, nextIter chan struct{}) {
+	nodes := gwo.Problem.Topology.NodeNames()
+	if err := gwo.scoreNew(ctx); err != nil {
+		gwo.stop(err)
+	}
+	for i := 0; i < gwo.MaxIter; i++ {
+		//*** Communicate with Shared Memory ***///
+		gwo.updateFront()
+
+		gwo.SharedMem.Lock()
+		*gwo.Front = gwo.ParetoFront
+		gwo.SharedMem.Unlock()
+
+		gwo.SharedMem.Lock()
+		if gwo.SharedMem.Transform == 2 {
+			pso := NewPSO(gwo.Problem, gwo.SharedMem, gwo.NumParticles, gwo.MaxIter-i, gwo.Rand)
+			pso.Front = gwo.Front // keep publishing to this swarm's slot
+			pso.Workers = gwo.Workers
+			pso.stop = gwo.stop
+			for j := 0; j < gwo.NumParticles/2; j++ {
+				pso.Particles[j] = gwo.Particles[j]
+			}
+			gwo.SharedMem.Transform = 0
+			gwo.SharedMem.Unlock()
+			pso.Cooperate(ctx, abDone, bDone, done, nextIter)
+			return
+		}
+		gwo.SharedMem.Unlock()
+
+		gwo.SharedMem.RLock()
+		newFront := gwo.SharedMem.MergedFront
+		gwo.SharedMem.RUnlock()
+
+		if len(newFront) > 0 {
+			worstIdx := 0
+			worstScore := -math.Inf(1)
+			for j, p := range gwo.Particles {
+				if p.BestScore > worstScore {
+					worstScore = p.BestScore
+					worstIdx = j
+				}
+			}
+			randIdx := gwo.Rand.Intn(len(newFront))
+			gwo.Particles[worstIdx].Solution = make(map[string]map[string]int)
+			for _, pm := range nodes {
+				gwo.Particles[worstIdx].Solution[pm] = make(map[string]int)
+			}
+			common.CopySolution(gwo.Particles[worstIdx].Solution, newFront[randIdx].Solution)
+			score, err := gwo.Problem.Evaluate(gwo.Particles[worstIdx].Solution)
+			if err != nil {
+				gwo.stop(err)
+			}
+			gwo.Particles[worstIdx].BestScore = score
+		}
+		bDone <- struct{}{} // Signal that critical section B is done
+		abDone.Done()       // Signal that B is done for C to proceed
+
+		//*** Original GWO Part ***///
+		if err := gwo.iterate(ctx, i); err != nil {
+			gwo.stop(err)
+		}
+		// Signal completion of this iteration
+		done <- struct{}{}
+		// Wait for the next iteration signal
+		if _, ok := <-nextIter; !ok {
+			break
+		}
+	}
+}
+
+// scoreNew scores the particles not scored yet and ranks them against
+// alpha, beta and delta.
+func (gwo *GWO) scoreNew(ctx context.Context) error {
+	if err := gwo.Problem.scoreNew(ctx, gwo.Particles, gwo.Workers); err != nil {
+		return err
+	}
+	for _, p := range gwo.Particles {
+		gwo.rankLeader(p)
+	}
+	gwo.ParetoFront = []common.Particle{gwo.Alpha} // Initial Pareto front
+	return nil
+}
+
+// rankLeader makes p alpha, beta or delta if it beats one of them.
+func (gwo *GWO) rankLeader(p common.Particle) {
+	if p.BestScore < gwo.Alpha.BestScore {
+		gwo.Delta = gwo.Beta
+		gwo.Beta = gwo.Alpha
+		gwo.Alpha = p
+	} else if p.BestScore < gwo.Beta.BestScore {
+		gwo.Delta = gwo.Beta
+		gwo.Beta = p
+	} else if p.BestScore < gwo.Delta.BestScore {
+		gwo.Delta = p
+	}
+}
+
+// updateFront rebuilds ParetoFront from the current particles.
+func (gwo *GWO) updateFront() {
+	gwo.ParetoFront = []common.Particle{}
+	for _, m := range gwo.Problem.frontMembers(gwo.Particles, SourceGWO, gwo.Workers) {
+		gwo.ParetoFront = updateParetoFront(gwo.ParetoFront, m)
+	}
+}
+
+// iterate moves every particle once, following the leaders of the previous
+// iteration, and updates the personal bests and alpha, beta and delta. The
+// moves draw from gwo.Rand in a fixed order, so they stay sequential; the
+// particles are then scored concurrently.
+func (gwo *GWO) iterate(ctx context.Context, i int) error {
+	nodes := gwo.Problem.Topology.NodeNames()
+	// Update a (linearly decreases from 0.8 to 0.2)
+	a := 0.8 - float64(i)/float64(gwo.MaxIter)*(0.8-0.2)
+	var solutions []map[string]map[string]int
+	for j := range gwo.Particles {
+		if ctx.Err() != nil {
+			break // finish the iteration with the particles moved so far
+		}
+		if gwo.Rand.Float64() < a {
+			// Transfer operation for exploration
+			transferOperation(&gwo.Particles[j], gwo.Problem, gwo.Rand)
+		} else if len(gwo.ParetoFront) > 0 {
+			// Copy operation from alpha, beta, or delta
+			leader := gwo.Alpha
+			switch gwo.Rand.Intn(3) {
+			case 1:
+				leader = gwo.Beta
+			case 2:
+				leader = gwo.Delta
+			}
+			rows := selectRandomRows(gwo.Rand, 1, len(gwo.Problem.Topology.Services)) // Single row as per paper
+			copyOperation(&gwo.Particles[j], leader.Solution, rows, gwo.Problem)
+		}
+		solutions = append(solutions, gwo.Particles[j].Solution)
+	}
+	scores, err := gwo.Problem.evaluateAll(ctx, solutions, gwo.Workers)
+	if stoppedBy(ctx, err) {
+		fmt.Printf("gwo: stopped before iteration %d was scored: %v\n", i, err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for j, score := range scores {
+		// Update personal best
+		if score < gwo.Particles[j].BestScore {
+			gwo.Particles[j].BestScore = score
+			gwo.Particles[j].BestSolution = make(map[string]map[string]int)
+			for _, pm := range nodes {
+				gwo.Particles[j].BestSolution[pm] = make(map[string]int)
+			}
+			common.CopySolution(gwo.Particles[j].BestSolution, gwo.Particles[j].Solution)
+		}
+
+		gwo.rankLeader(gwo.Particles[j])
+	}
+	return nil
+}
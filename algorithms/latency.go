@@ -0,0 +1,202 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"optimizer/common"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of latency objective.
+const (
+	LatencyMean       = "mean"       // average predicted trace duration
+	LatencyPercentile = "percentile" // percentile of each root operation's durations
+	LatencySLO        = "slo"        // share of traces missing their root operation's target
+)
+
+// LatencyObjective is how the predicted trace durations of a deployment are
+// reduced to the latency the optimizers minimize.
+type LatencyObjective struct {
+	Kind       string  // one of the kinds above; empty means LatencyMean
+	Percentile float64 // LatencyPercentile: in (0, 100]
+}
+
+// ParseLatencyObjective parses "mean", "slo" or a percentile such as "p95"
+// or "p99.9".
+func ParseLatencyObjective(s string) (LatencyObjective, error) {
+	switch s {
+	case "", LatencyMean:
+		return LatencyObjective{Kind: LatencyMean}, nil
+	case LatencySLO:
+		return LatencyObjective{Kind: LatencySLO}, nil
+	}
+	if rest, ok := strings.CutPrefix(s, "p"); ok {
+		q, err := strconv.ParseFloat(rest, 64)
+		if err == nil && q > 0 && q <= 100 {
+			return LatencyObjective{Kind: LatencyPercentile, Percentile: q}, nil
+		}
+	}
+	return LatencyObjective{}, fmt.Errorf("unknown latency objective %q (want mean, pN or slo)", s)
+}
+
+// String returns the objective in the form ParseLatencyObjective reads.
+func (o LatencyObjective) String() string {
+	if o.Kind == LatencyPercentile {
+		return "p" + strconv.FormatFloat(o.Percentile, 'f', -1, 64)
+	}
+	if o.Kind == "" {
+		return LatencyMean
+	}
+	return o.Kind
+}
+
+// SLOTargets are the latency targets in ms of root operations.
+type SLOTargets struct {
+	Default   float64            `json:"default"`   // target of operations missing from Endpoints; 0 means none
+	Endpoints map[string]float64 `json:"endpoints"` // root operation -> target
+}
+
+// Target returns the target of the root operation, if it has one.
+func (s SLOTargets) Target(operation string) (float64, bool) {
+	if t, ok := s.Endpoints[operation]; ok && t > 0 {
+		return t, true
+	}
+	return s.Default, s.Default > 0
+}
+
+// SetLatencyObjective parses spec (see ParseLatencyObjective) and makes it
+// the latency p minimizes. An SLO objective needs targets in p.SLO.
+func (p *Problem) SetLatencyObjective(spec string) error {
+	objective, err := ParseLatencyObjective(spec)
+	if err != nil {
+		return err
+	}
+	if objective.Kind == LatencySLO && p.SLO.Default <= 0 && len(p.SLO.Endpoints) == 0 {
+		return fmt.Errorf("latency objective slo: no SLO targets loaded")
+	}
+	p.Latency = objective
+	return nil
+}
+
+// latency reduces the predicted durations in µs of p's traces under
+// p.Latency: the mean in ms, the trace-weighted average of each root
+// operation's percentile in ms, or the percentage of traces slower than
+// their root operation's target. The percentage keeps the SLO objective on
+// a scale comparable to milliseconds against the DepIC term.
+func (p *Problem) latency(durations []float64) float64 {
+	if len(durations) == 0 {
+		return 0
+	}
+	switch p.Latency.Kind {
+	case LatencyPercentile:
+		groups := p.byRootOperation(durations)
+		ops := make([]string, 0, len(groups))
+		for op := range groups {
+			ops = append(ops, op)
+		}
+		sort.Strings(ops) // fixed order keeps the float sum reproducible
+		total := 0.0
+		for _, op := range ops {
+			total += percentile(groups[op], p.Latency.Percentile) * float64(len(groups[op]))
+		}
+		return total / float64(len(durations)) / 1000
+	case LatencySLO:
+		missed := 0
+		for i, d := range durations {
+			if target, ok := p.SLO.Target(p.rootOperationOf(i)); ok && d/1000 > target {
+				missed++
+			}
+		}
+		return 100 * float64(missed) / float64(len(durations))
+	default:
+		var total int64
+		for _, d := range durations {
+			total += int64(d)
+		}
+		// same truncation as common.CalculateAverageDuration
+		return float64(total / int64(len(durations)) / 1000)
+	}
+}
+
+// byRootOperation groups the durations of p's traces by root operation.
+func (p *Problem) byRootOperation(durations []float64) map[string][]float64 {
+	groups := make(map[string][]float64)
+	for i, d := range durations {
+		op := p.rootOperationOf(i)
+		groups[op] = append(groups[op], d)
+	}
+	return groups
+}
+
+// rootOperationOf returns the operation of the first root span of the i-th
+// trace of p, which names the trace's type.
+func (p *Problem) rootOperationOf(i int) string {
+	if p.rootOperations != nil {
+		return p.rootOperations[i]
+	}
+	return rootOperation(p.Traces.Data[i], p.spanGraphOf(i))
+}
+
+func rootOperation(trace common.Trace, g spanGraph) string {
+	if len(g.roots) == 0 || len(g.roots[0]) == 0 {
+		return ""
+	}
+	return trace.Spans[g.roots[0][0]].OperationName
+}
+
+// percentile returns the q-th percentile of values by the nearest-rank
+// method. values is sorted in place.
+func percentile(values []float64, q float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	rank := int(math.Ceil(q / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+	return values[rank-1]
+}
+
+// EndpointLatency is the predicted latency distribution of one root
+// operation, in ms.
+type EndpointLatency struct {
+	Operation string  `json:"operation"`
+	Traces    int     `json:"traces"`
+	Mean      float64 `json:"mean"`
+	P50       float64 `json:"p50"`
+	P95       float64 `json:"p95"`
+	P99       float64 `json:"p99"`
+	Target    float64 `json:"target,omitempty"` // SLO target; 0 if none
+	Missed    float64 `json:"missed"`           // share of traces slower than Target
+}
+
+// LatencyReport returns the predicted latency of every root operation under
+// solution, sorted by operation.
+func (p *Problem) LatencyReport(solution map[string]map[string]int) []EndpointLatency {
+	var report []EndpointLatency
+	for op, ds := range p.byRootOperation(p.predictDurations(solution)) {
+		e := EndpointLatency{Operation: op, Traces: len(ds)}
+		target, hasTarget := p.SLO.Target(op)
+		missed := 0
+		for _, d := range ds {
+			e.Mean += d
+			if hasTarget && d/1000 > target {
+				missed++
+			}
+		}
+		e.Mean /= float64(len(ds)) * 1000
+		e.P50 = percentile(ds, 50) / 1000
+		e.P95 = percentile(ds, 95) / 1000
+		e.P99 = percentile(ds, 99) / 1000
+		if hasTarget {
+			e.Target = target
+			e.Missed = float64(missed) / float64(len(ds))
+		}
+		report = append(report, e)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Operation < report[j].Operation })
+	return report
+}
@@ -0,0 +1,75 @@
+package algorithms
+
+import (
+	"math"
+	"optimizer/common"
+	"testing"
+)
+
+func TestParseLatencyObjective(t *testing.T) {
+	tests := []struct {
+		in   string
+		want LatencyObjective
+		bad  bool
+	}{
+		{in: "", want: LatencyObjective{Kind: LatencyMean}},
+		{in: "mean", want: LatencyObjective{Kind: LatencyMean}},
+		{in: "slo", want: LatencyObjective{Kind: LatencySLO}},
+		{in: "p95", want: LatencyObjective{Kind: LatencyPercentile, Percentile: 95}},
+		{in: "p99.9", want: LatencyObjective{Kind: LatencyPercentile, Percentile: 99.9}},
+		{in: "p0", bad: true},
+		{in: "p101", bad: true},
+		{in: "median", bad: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLatencyObjective(tt.in)
+		if tt.bad {
+			if err == nil {
+				t.Errorf("ParseLatencyObjective(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLatencyObjective(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+		if tt.in != "" && got.String() != tt.in {
+			t.Errorf("%q parses back to %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestLatencyObjectives(t *testing.T) {
+	// four traces of /a and one of /b; durations in µs
+	problem := &Problem{Topology: &common.Topology{}}
+	for _, op := range []string{"/a", "/a", "/a", "/a", "/b"} {
+		root := testSpan("root", "", 0, 0)
+		root.OperationName = op
+		problem.Traces.Data = append(problem.Traces.Data, common.Trace{Spans: []common.Span{root}})
+	}
+	problem.index()
+	durations := []float64{10e3, 20e3, 30e3, 100e3, 50e3}
+	problem.SLO = SLOTargets{Endpoints: map[string]float64{"/a": 25}, Default: 60}
+
+	tests := []struct {
+		spec string
+		want float64
+	}{
+		{"mean", 42},
+		{"p50", (20*4 + 50) / 5.0},
+		{"p100", (100*4 + 50) / 5.0},
+		{"slo", 40}, // 30 and 100 ms miss the 25 ms target of /a
+	}
+	for _, tt := range tests {
+		if err := problem.SetLatencyObjective(tt.spec); err != nil {
+			t.Fatalf("SetLatencyObjective(%q): %v", tt.spec, err)
+		}
+		if got := problem.latency(append([]float64(nil), durations...)); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: latency = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+
+	problem.SLO = SLOTargets{}
+	if err := problem.SetLatencyObjective("slo"); err == nil {
+		t.Errorf("slo objective without targets accepted")
+	}
+}
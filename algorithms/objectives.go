@@ -0,0 +1,102 @@
+package algorithms
+
+import (
+	"context"
+	"optimizer/common"
+)
+
+// Indices into Particle.Objectives. Every objective is minimized.
+const (
+	ObjLatency    = iota // predicted latency under Problem.Latency: ms, or % of traces missing their SLO
+	ObjCost              // requested share of the cluster's CPU plus memory
+	ObjColocation        // negated DepIC co-location score
+	ObjReplicas          // total number of replicas
+	ObjSpend             // price per hour under Problem.Cost
+	ObjEnergy            // Wh per hour under Problem.Power
+	NumObjectives
+)
+
+// ObjectiveNames names the objectives in index order.
+var ObjectiveNames = []string{"latency", "cost", "colocation", "replicas", "spend", "energy"}
+
+// Sources of front members.
+const (
+	SourcePSO = "pso"
+	SourceGWO = "gwo"
+)
+
+// infeasiblePenalty is the score of a deployment that breaks a constraint.
+const infeasiblePenalty = 999999999
+
+// Objectives returns the objective vector of solution and whether it
+// satisfies the constraints.
+func (p *Problem) Objectives(solution map[string]map[string]int) ([]float64, bool) {
+	objectives := make([]float64, NumObjectives)
+	objectives[ObjLatency] = p.predictLatency(solution)
+	objectives[ObjCost] = p.resourceCost(solution)
+	objectives[ObjColocation] = -p.colocationScore(solution)
+	objectives[ObjSpend] = p.spend(solution)
+	objectives[ObjEnergy] = p.energy(solution)
+	for _, services := range solution {
+		for _, replicas := range services {
+			objectives[ObjReplicas] += float64(replicas)
+		}
+	}
+	return objectives, p.checkConstraints(solution)
+}
+
+// resourceCost returns the CPU and memory requested by solution, each as a
+// share of what all nodes offer.
+func (p *Problem) resourceCost(solution map[string]map[string]int) float64 {
+	var cpu, memory, totalCPU, totalMemory int
+	for _, node := range p.Topology.NodeNames() {
+		totalCPU += p.NodeConstraints[node].CPU
+		totalMemory += p.NodeConstraints[node].Memory
+		for service, replicas := range solution[node] {
+			cpu += p.ServiceConstraints[service].CPU * replicas
+			memory += p.ServiceConstraints[service].Memory * replicas
+		}
+	}
+	cost := 0.0
+	if totalCPU > 0 {
+		cost += float64(cpu) / float64(totalCPU)
+	}
+	if totalMemory > 0 {
+		cost += float64(memory) / float64(totalMemory)
+	}
+	return cost
+}
+
+// frontMember returns a snapshot of the particle's current solution for a
+// Pareto front, with its objectives, feasibility and source. The solution
+// is copied so the swarm can keep moving the particle.
+func (p *Problem) frontMember(particle common.Particle, source string) common.Particle {
+	solution := p.NewSolution()
+	common.CopySolution(solution, particle.Solution)
+	objectives, feasible := p.Objectives(solution)
+	var score float64
+	if feasible {
+		score = feasibleScore(objectives[ObjLatency] + objectives[ObjColocation] +
+			p.SpendWeight*objectives[ObjSpend] + p.EnergyWeight*objectives[ObjEnergy])
+	} else {
+		score = p.infeasibleScore(solution)
+	}
+	return common.Particle{
+		Solution:     solution,
+		BestSolution: solution,
+		BestScore:    score,
+		Objectives:   objectives,
+		Feasible:     feasible,
+		Source:       source,
+	}
+}
+
+// frontMembers returns the front member of every particle, computed on up to
+// workers goroutines.
+func (p *Problem) frontMembers(particles []common.Particle, source string, workers int) []common.Particle {
+	members := make([]common.Particle, len(particles))
+	forEach(context.Background(), len(particles), workers, func(i int) {
+		members[i] = p.frontMember(particles[i], source)
+	})
+	return members
+}
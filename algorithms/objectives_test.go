@@ -0,0 +1,53 @@
+package algorithms
+
+import (
+	"optimizer/common"
+	"testing"
+)
+
+func member(feasible bool, objectives ...float64) common.Particle {
+	return common.Particle{Objectives: objectives, Feasible: feasible}
+}
+
+func TestDominates(t *testing.T) {
+	tests := []struct {
+		name   string
+		p1, p2 common.Particle
+		want   bool
+	}{
+		{"better in all", member(true, 1, 1), member(true, 2, 2), true},
+		{"better in one", member(true, 1, 2), member(true, 2, 2), true},
+		{"equal", member(true, 1, 2), member(true, 1, 2), false},
+		{"trade-off", member(true, 1, 3), member(true, 2, 2), false},
+		{"feasible beats infeasible", member(true, 9, 9), member(false, 1, 1), true},
+		{"infeasible never dominates", member(false, 1, 1), member(true, 9, 9), false},
+	}
+	for _, tt := range tests {
+		if got := dominates(tt.p1, tt.p2); got != tt.want {
+			t.Errorf("%s: dominates = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNonDominatedSort(t *testing.T) {
+	fronts := nonDominatedSort([]common.Particle{
+		member(true, 3, 3), // rank 1
+		member(true, 1, 4), // rank 0
+		member(true, 2, 2), // rank 0
+		member(true, 2, 2), // duplicate, dropped
+		member(true, 4, 4), // rank 2
+		member(false, 0, 0),
+	})
+	want := []int{2, 1, 1, 1}
+	if len(fronts) != len(want) {
+		t.Fatalf("got %d fronts, want %d", len(fronts), len(want))
+	}
+	for i, front := range fronts {
+		if len(front) != want[i] {
+			t.Errorf("front %d has %d members, want %d", i, len(front), want[i])
+		}
+	}
+	if fronts[3][0].Feasible {
+		t.Errorf("infeasible particle should be ranked last")
+	}
+}
@@ -0,0 +1,274 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"optimizer/common"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Result is what every optimizer reports back.
+type Result struct {
+	Algorithm    string        `json:"algorithm"`
+	BestSolution Solution      `json:"bestSolution"`
+	BestScore    float64       `json:"bestScore"`
+	History      []float64     `json:"history"` // best score after each iteration
+	Elapsed      time.Duration `json:"elapsed"`
+	Stopped      string        `json:"stopped,omitempty"` // why the run ended early, if it did
+	Seed         int64         `json:"seed"`              // replays the run when passed as Options.Seed
+	// Front is the final Pareto front of multi-objective optimizers.
+	Front []FrontMember `json:"front,omitempty"`
+	// Contributed counts the front members each swarm of a hybrid found,
+	// after each iteration.
+	Contributed []map[string]int `json:"contributed,omitempty"`
+}
+
+// Options holds the tunables of all optimizers; each one reads the fields
+// that apply to it and falls back to its own default for zero values.
+type Options struct {
+	Iterations  int           // dpso, pso, gwo, ps-gwca
+	Particles   int           // dpso, pso, gwo, ps-gwca
+	BranchSize  int           // tigo
+	HistoryFile string        // dpso: per-iteration CSV, not written when empty
+	Timeout     time.Duration // wall-clock budget of a run; none when zero
+	Seed        int64         // seed of the run's random source; taken from the clock when zero
+	Workers     int           // dpso, pso, gwo, ps-gwca: concurrent evaluations (default GOMAXPROCS)
+}
+
+// Validate rejects negative counts; zero stands for the default.
+func (o Options) Validate() error {
+	for name, value := range map[string]int{
+		"iterations":  o.Iterations,
+		"particles":   o.Particles,
+		"branch size": o.BranchSize,
+		"workers":     o.Workers,
+	} {
+		if value < 0 {
+			return fmt.Errorf("%s must not be negative, got %d", name, value)
+		}
+	}
+	return nil
+}
+
+// Optimizer searches a deployment for a problem. When ctx is cancelled or
+// its deadline passes, Optimize stops early and returns the best solution
+// found so far with Result.Stopped set.
+type Optimizer interface {
+	Optimize(ctx context.Context, problem *Problem) (*Result, error)
+}
+
+// Constructor builds an optimizer from options.
+type Constructor func(opts Options) Optimizer
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Constructor)
+)
+
+// Register makes an optimizer available by name. It panics if the name is
+// already taken.
+func Register(name string, constructor Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("algorithms: optimizer %q registered twice", name))
+	}
+	registry[name] = constructor
+}
+
+// New returns the optimizer registered under name.
+func New(name string, opts Options) (Optimizer, error) {
+	registryMu.RLock()
+	constructor, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm %q (available: %v)", name, Names())
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return constructor(opts), nil
+}
+
+// Names lists the registered optimizers in alphabetical order.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register("dpso", func(opts Options) Optimizer { return dpsoOptimizer{opts} })
+	Register("pso", func(opts Options) Optimizer { return psoOptimizer{opts} })
+	Register("gwo", func(opts Options) Optimizer { return gwoOptimizer{opts} })
+	Register("ps-gwca", func(opts Options) Optimizer { return psgwcaOptimizer{opts} })
+	Register("tigo", func(opts Options) Optimizer { return tigoOptimizer{opts} })
+}
+
+type dpsoOptimizer struct{ opts Options }
+
+func (o dpsoOptimizer) Optimize(ctx context.Context, problem *Problem) (*Result, error) {
+	start := time.Now()
+	ctx, cancel := withBudget(ctx, o.opts)
+	defer cancel()
+	rng, seed := newRand(o.opts.Seed)
+	dpso := NewDPSO(problem, orDefault(o.opts.Particles, 30), orDefault(o.opts.Iterations, 100), rng)
+	dpso.HistoryFile = o.opts.HistoryFile
+	dpso.Workers = orDefault(o.opts.Workers, dpso.Workers)
+	if err := dpso.Optimize(ctx); err != nil {
+		return nil, err
+	}
+	return &Result{
+		Algorithm:    "dpso",
+		BestSolution: dpso.BestSolution,
+		BestScore:    dpso.BestScore,
+		History:      dpso.History,
+		Elapsed:      time.Since(start),
+		Stopped:      stopped(ctx),
+		Seed:         seed,
+	}, nil
+}
+
+type psoOptimizer struct{ opts Options }
+
+func (o psoOptimizer) Optimize(ctx context.Context, problem *Problem) (*Result, error) {
+	start := time.Now()
+	ctx, cancel := withBudget(ctx, o.opts)
+	defer cancel()
+	rng, seed := newRand(o.opts.Seed)
+	pso := NewPSO(problem, &common.SharedMemory{}, orDefault(o.opts.Particles, 300), orDefault(o.opts.Iterations, 100), rng)
+	pso.Workers = orDefault(o.opts.Workers, pso.Workers)
+	if err := pso.Optimize(ctx); err != nil {
+		return nil, err
+	}
+	return &Result{
+		Algorithm:    "pso",
+		BestSolution: pso.BestSolution,
+		BestScore:    pso.BestScore,
+		History:      pso.History,
+		Elapsed:      time.Since(start),
+		Stopped:      stopped(ctx),
+		Seed:         seed,
+		Front:        NewFront(pso.ParetoFront),
+	}, nil
+}
+
+type gwoOptimizer struct{ opts Options }
+
+func (o gwoOptimizer) Optimize(ctx context.Context, problem *Problem) (*Result, error) {
+	start := time.Now()
+	ctx, cancel := withBudget(ctx, o.opts)
+	defer cancel()
+	rng, seed := newRand(o.opts.Seed)
+	gwo := NewGWO(problem, &common.SharedMemory{}, orDefault(o.opts.Particles, 300), orDefault(o.opts.Iterations, 100), rng)
+	gwo.Workers = orDefault(o.opts.Workers, gwo.Workers)
+	if err := gwo.Optimize(ctx); err != nil {
+		return nil, err
+	}
+	return &Result{
+		Algorithm:    "gwo",
+		BestSolution: gwo.Alpha.BestSolution,
+		BestScore:    gwo.Alpha.BestScore,
+		History:      gwo.History,
+		Elapsed:      time.Since(start),
+		Stopped:      stopped(ctx),
+		Seed:         seed,
+		Front:        NewFront(gwo.ParetoFront),
+	}, nil
+}
+
+type psgwcaOptimizer struct{ opts Options }
+
+func (o psgwcaOptimizer) Optimize(ctx context.Context, problem *Problem) (*Result, error) {
+	start := time.Now()
+	ctx, cancel := withBudget(ctx, o.opts)
+	defer cancel()
+	rng, seed := newRand(o.opts.Seed)
+	ps := NewPSGWCA(problem, orDefault(o.opts.Particles, 300), orDefault(o.opts.Iterations, 100), rng)
+	ps.Workers = orDefault(o.opts.Workers, ps.Workers)
+	best, err := ps.Optimize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	score, err := problem.Evaluate(best.BestSolution)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Algorithm:    "ps-gwca",
+		BestSolution: best.BestSolution,
+		BestScore:    score,
+		History:      ps.History,
+		Elapsed:      time.Since(start),
+		Stopped:      stopped(ctx),
+		Seed:         seed,
+		Front:        NewFront(ps.SharedMem.MergedFront),
+		Contributed:  ps.Contributed,
+	}, nil
+}
+
+type tigoOptimizer struct{ opts Options }
+
+func (o tigoOptimizer) Optimize(ctx context.Context, problem *Problem) (*Result, error) {
+	start := time.Now()
+	if len(problem.Topology.CloudNodes()) == 0 {
+		return nil, fmt.Errorf("tigo: topology has no cloud node")
+	}
+	ctx, cancel := withBudget(ctx, o.opts)
+	defer cancel()
+	rng, seed := newRand(o.opts.Seed)
+	tigo := NewTIGO(problem, orDefault(o.opts.BranchSize, 5), rng)
+	best, score, err := tigo.Optimize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Algorithm:    "tigo",
+		BestSolution: best,
+		BestScore:    score,
+		History:      tigo.History,
+		Elapsed:      time.Since(start),
+		Stopped:      stopped(ctx),
+		Seed:         seed,
+	}, nil
+}
+
+// newRand returns the random source of a run and the seed it was built
+// from.
+func newRand(seed int64) (*rand.Rand, int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed)), seed
+}
+
+// withBudget derives a context that also ends after opts.Timeout.
+func withBudget(ctx context.Context, opts Options) (context.Context, context.CancelFunc) {
+	if opts.Timeout > 0 {
+		return context.WithTimeout(ctx, opts.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// stopped returns why ctx ended, or "" if it has not.
+func stopped(ctx context.Context) string {
+	if err := ctx.Err(); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func orDefault(value, fallback int) int {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,132 @@
+package algorithms
+
+import (
+	"context"
+	"optimizer/common"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	problem := testProblem(t)
+
+	for _, name := range []string{"dpso", "pso", "gwo"} {
+		optimizer, err := New(name, Options{Particles: 4, Iterations: 3})
+		if err != nil {
+			t.Fatalf("New(%s): %v", name, err)
+		}
+		result, err := optimizer.Optimize(context.Background(), problem)
+		if err != nil {
+			t.Fatalf("%s: Optimize: %v", name, err)
+		}
+		if result.Algorithm != name {
+			t.Errorf("Algorithm = %q, want %s", result.Algorithm, name)
+		}
+		if len(result.History) != 3 {
+			t.Errorf("%s: len(History) = %d, want 3", name, len(result.History))
+		}
+		if got, _ := problem.Evaluate(result.BestSolution); got != result.BestScore {
+			t.Errorf("%s: BestScore = %f, but best solution evaluates to %f", name, result.BestScore, got)
+		}
+	}
+
+	if _, err := New("no-such-algorithm", Options{}); err == nil {
+		t.Error("New(no-such-algorithm) succeeded")
+	}
+	if _, err := New("ps-gwca", Options{Iterations: -1}); err == nil {
+		t.Error("New(ps-gwca) accepted -1 iterations")
+	}
+}
+
+func TestOptimizeStopsEarly(t *testing.T) {
+	problem := testProblem(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, name := range Names() {
+		optimizer, err := New(name, Options{Particles: 6, Iterations: 1000})
+		if err != nil {
+			t.Fatalf("New(%s): %v", name, err)
+		}
+		result, err := optimizer.Optimize(ctx, problem)
+		if err != nil {
+			t.Fatalf("%s: Optimize: %v", name, err)
+		}
+		if result.Stopped == "" {
+			t.Errorf("%s: Stopped is empty after cancellation", name)
+		}
+		if len(result.BestSolution) == 0 {
+			t.Errorf("%s: no best-so-far solution", name)
+		}
+		if len(result.History) >= 1000 {
+			t.Errorf("%s: ran all %d iterations", name, len(result.History))
+		}
+	}
+
+	optimizer, _ := New("dpso", Options{Particles: 6, Iterations: 1000, Timeout: time.Nanosecond})
+	result, err := optimizer.Optimize(context.Background(), problem)
+	if err != nil {
+		t.Fatalf("Optimize: %v", err)
+	}
+	if result.Stopped != context.DeadlineExceeded.Error() {
+		t.Errorf("Stopped = %q, want %q", result.Stopped, context.DeadlineExceeded)
+	}
+}
+
+func TestSeedReproducible(t *testing.T) {
+	problem := testProblem(t)
+	// tigo tries every span range of every trace; keep its search short
+	var short []common.Trace
+	for _, trace := range problem.Traces.Data {
+		if len(trace.Spans) <= 6 && len(short) < 3 {
+			short = append(short, trace)
+		}
+	}
+	problem.Traces.Data = short
+	problem.index()
+	for _, name := range Names() {
+		var results [2]*Result
+		for i := range results {
+			optimizer, err := New(name, Options{Particles: 6, Iterations: 4, BranchSize: 2, Seed: 42})
+			if err != nil {
+				t.Fatalf("New(%s): %v", name, err)
+			}
+			if results[i], err = optimizer.Optimize(context.Background(), problem); err != nil {
+				t.Fatalf("%s: Optimize: %v", name, err)
+			}
+		}
+		a, b := results[0], results[1]
+		if a.Seed != 42 {
+			t.Errorf("%s: Seed = %d, want 42", name, a.Seed)
+		}
+		if a.BestScore != b.BestScore || !reflect.DeepEqual(a.BestSolution, b.BestSolution) ||
+			!reflect.DeepEqual(a.History, b.History) || !reflect.DeepEqual(a.Front, b.Front) {
+			t.Errorf("%s: two runs with the same seed differ", name)
+		}
+	}
+}
+
+func TestNegativeFitness(t *testing.T) {
+	problem := testProblem(t)
+	for k := range problem.Heatmap {
+		problem.Heatmap[k] *= 1e6 // co-location outweighs any latency
+	}
+	solution := problem.NewSolution()
+	for _, service := range problem.Topology.Services {
+		solution["vm1"][service] = 1
+	}
+	if _, err := problem.Evaluate(solution); err == nil {
+		t.Error("Evaluate accepted a negative fitness")
+	}
+
+	for _, name := range Names() {
+		optimizer, err := New(name, Options{Particles: 6, Iterations: 2, BranchSize: 2, Seed: 1})
+		if err != nil {
+			t.Fatalf("New(%s): %v", name, err)
+		}
+		if _, err := optimizer.Optimize(context.Background(), problem); err == nil {
+			t.Errorf("%s: Optimize hid a negative fitness", name)
+		}
+	}
+}
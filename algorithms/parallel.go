@@ -0,0 +1,107 @@
+package algorithms
+
+import (
+	"context"
+	"errors"
+	"math"
+	"optimizer/common"
+	"runtime"
+	"sync"
+)
+
+// defaultWorkers is the size of the evaluation pool when none is configured.
+func defaultWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// forEach calls fn(i) for i in [0, n) on up to workers goroutines. It stops
+// handing out indices once ctx is done and then returns ctx.Err(); calls
+// already started still finish. fn must only write to state owned by i.
+func forEach(ctx context.Context, n, workers int, fn func(i int)) error {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+
+	var err error
+	for i := 0; i < n; i++ {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return err
+}
+
+// evaluateAll scores solutions with up to workers goroutines. Evaluate only
+// reads p, so this gives the same scores as calling it in a loop. Solutions
+// left unscored once ctx is done score +Inf. A failed evaluation is reported
+// before ctx.Err().
+func (p *Problem) evaluateAll(ctx context.Context, solutions []map[string]map[string]int, workers int) ([]float64, error) {
+	scores := make([]float64, len(solutions))
+	for i := range scores {
+		scores[i] = math.Inf(1)
+	}
+	errs := make([]error, len(solutions))
+	stopped := forEach(ctx, len(solutions), workers, func(i int) {
+		scores[i], errs[i] = p.Evaluate(solutions[i])
+	})
+	if err := errors.Join(errs...); err != nil {
+		return scores, err
+	}
+	return scores, stopped
+}
+
+// stoppedBy reports whether err is ctx ending rather than a failure.
+func stoppedBy(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err())
+}
+
+// scoreNew scores the particles whose BestScore is still +Inf, that is the
+// random ones a constructor built, and makes their solution their personal
+// best. It stops once ctx is done, except that the first of them is always
+// scored so that a stopped run still has a best solution, and fails if a
+// particle cannot be scored.
+func (p *Problem) scoreNew(ctx context.Context, particles []common.Particle, workers int) error {
+	var pending []int
+	var solutions []map[string]map[string]int
+	for i := range particles {
+		if math.IsInf(particles[i].BestScore, 1) {
+			pending = append(pending, i)
+			solutions = append(solutions, particles[i].Solution)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	first, err := p.Evaluate(solutions[0])
+	if err != nil {
+		return err
+	}
+	scores, err := p.evaluateAll(ctx, solutions[1:], workers)
+	if err != nil && !stoppedBy(ctx, err) {
+		return err
+	}
+	scores = append([]float64{first}, scores...)
+	for k, i := range pending {
+		particles[i].BestScore = scores[k]
+		common.CopySolution(particles[i].BestSolution, particles[i].Solution)
+	}
+	return nil
+}
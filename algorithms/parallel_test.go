@@ -0,0 +1,57 @@
+package algorithms
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+	seen := make([]int32, 100)
+	if err := forEach(context.Background(), len(seen), 4, func(i int) {
+		atomic.AddInt32(&seen[i], 1)
+	}); err != nil {
+		t.Fatalf("forEach: %v", err)
+	}
+	for i, n := range seen {
+		if n != 1 {
+			t.Errorf("index %d visited %d times", i, n)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls int32
+	err := forEach(ctx, 100, 4, func(int) { atomic.AddInt32(&calls, 1) })
+	if err != context.Canceled {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+	if calls != 0 {
+		t.Errorf("%d calls after cancellation", calls)
+	}
+}
+
+func TestScoreNewStops(t *testing.T) {
+	problem := testProblem(t)
+	dpso := NewDPSO(problem, 4, 1, testRand())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := problem.scoreNew(ctx, dpso.Particles, 2); err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range dpso.Particles {
+		if scored := !math.IsInf(p.BestScore, 1); scored != (i == 0) {
+			t.Errorf("particle %d scored = %v after cancellation", i, scored)
+		}
+	}
+
+	if err := problem.scoreNew(context.Background(), dpso.Particles, 2); err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range dpso.Particles {
+		if want, _ := problem.Evaluate(p.Solution); p.BestScore != want {
+			t.Errorf("particle %d: BestScore = %f, want %f", i, p.BestScore, want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package algorithms
+
+import (
+	"fmt"
+	"optimizer/common"
+	"strings"
+)
+
+// Violation is a constraint a solution breaks.
+type Violation struct {
+	Rule   string `json:"rule"`
+	Detail string `json:"detail"`
+}
+
+func (v Violation) String() string {
+	return v.Rule + ": " + v.Detail
+}
+
+// Violations returns every constraint solution breaks: the placement rules
+// in the order they are declared, then the capacity of the nodes.
+func (p *Problem) Violations(solution map[string]map[string]int) []Violation {
+	return append(p.placementViolations(solution), p.capacityViolations(solution)...)
+}
+
+// placementViolations checks that the entrypoint stays off the cloud and
+// that solution follows p.Placement.
+func (p *Problem) placementViolations(solution map[string]map[string]int) []Violation {
+	var violations []Violation
+	if on := p.nodesRunning(solution, p.Topology.Entrypoint, common.Node.IsCloud); len(on) > 0 {
+		violations = append(violations, Violation{
+			Rule:   "keep entrypoint " + p.Topology.Entrypoint + " off the cloud",
+			Detail: "runs on " + strings.Join(on, ", "),
+		})
+	}
+	if p.Placement == nil {
+		return violations
+	}
+
+	for _, rule := range p.Placement.Rules {
+		var detail string
+		switch rule.Type {
+		case common.RulePin:
+			if on := p.nodesRunning(solution, rule.Service, func(n common.Node) bool { return !rule.Matches(n) }); len(on) > 0 {
+				detail = "runs on " + strings.Join(on, ", ")
+			}
+		case common.RuleForbid:
+			if on := p.nodesRunning(solution, rule.Service, rule.Matches); len(on) > 0 {
+				detail = "runs on " + strings.Join(on, ", ")
+			}
+		case common.RuleColocate:
+			if on := p.nodesRunning(solution, rule.Service, func(n common.Node) bool { return solution[n.Name][rule.With] == 0 }); len(on) > 0 {
+				detail = "runs without " + rule.With + " on " + strings.Join(on, ", ")
+			}
+		case common.RuleSpread:
+			if on := p.nodesRunning(solution, rule.Service, nil); len(on) < rule.Min {
+				detail = fmt.Sprintf("runs on %d nodes", len(on))
+			}
+		case common.RuleReplicas:
+			replicas := 0
+			for _, node := range p.Topology.NodeNames() {
+				replicas += solution[node][rule.Service]
+			}
+			if replicas < rule.Min || (rule.Max > 0 && replicas > rule.Max) {
+				detail = fmt.Sprintf("has %d replicas", replicas)
+			}
+		case common.RuleLocality:
+			on := p.nodesRunning(solution, rule.Service, nil)
+			if len(on) > 1 {
+				detail = "runs on " + strings.Join(on, ", ")
+			} else if len(rule.Nodes) > 0 && len(p.nodesRunning(solution, rule.Service, rule.Matches)) < len(on) {
+				detail = "runs on " + on[0]
+			}
+		}
+		if detail != "" {
+			violations = append(violations, Violation{Rule: rule.String(), Detail: detail})
+		}
+	}
+	return violations
+}
+
+// nodesRunning returns the nodes that run replicas of service and, unless
+// match is nil, match.
+func (p *Problem) nodesRunning(solution map[string]map[string]int, service string, match func(common.Node) bool) []string {
+	var nodes []string
+	for _, node := range p.Topology.Nodes {
+		if solution[node.Name][service] > 0 && (match == nil || match(node)) {
+			nodes = append(nodes, node.Name)
+		}
+	}
+	return nodes
+}
+
+// allowedOn reports whether the pin, forbid and locality rules of p let
+// replicas of service run on node at all.
+func (p *Problem) allowedOn(service string, node common.Node) bool {
+	if service == p.Topology.Entrypoint && node.IsCloud() {
+		return false
+	}
+	if p.Placement == nil {
+		return true
+	}
+	for _, rule := range p.Placement.Rules {
+		if rule.Service != service {
+			continue
+		}
+		switch rule.Type {
+		case common.RulePin:
+			if !rule.Matches(node) {
+				return false
+			}
+		case common.RuleForbid:
+			if rule.Matches(node) {
+				return false
+			}
+		case common.RuleLocality:
+			if len(rule.Nodes) > 0 && !rule.Matches(node) {
+				return false
+			}
+		}
+	}
+	return true
+}
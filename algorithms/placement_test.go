@@ -0,0 +1,80 @@
+package algorithms
+
+import (
+	"optimizer/common"
+	"strings"
+	"testing"
+)
+
+func TestPlacementViolations(t *testing.T) {
+	topo := testTopology(t)
+	rules, err := common.LoadPlacementRules("../placement_example.json", topo)
+	if err != nil {
+		t.Fatalf("LoadPlacementRules: %v", err)
+	}
+	problem := &Problem{Topology: topo, Placement: rules}
+
+	solution := problem.NewSolution()
+	solution["vm1"]["frontend"] = 1
+	solution["vm1"]["redis-cart"] = 1
+	solution["vm1"]["cartservice"] = 2
+	solution["vm1"]["productcatalogservice"] = 1
+	solution["vm2"]["productcatalogservice"] = 1
+	solution["vm3"]["paymentservice"] = 1
+	if v := problem.Violations(solution); len(v) != 0 {
+		t.Fatalf("feasible placement breaks %v", v)
+	}
+
+	solution["asus"]["frontend"] = 3             // entrypoint, forbid and replicas
+	solution["vm2"]["redis-cart"] = 1            // locality
+	solution["vm3"]["cartservice"] = 1           // colocate
+	solution["vm2"]["productcatalogservice"] = 0 // spread
+	solution["asus"]["paymentservice"] = 1       // pin
+	var got []string
+	for _, v := range problem.Violations(solution) {
+		got = append(got, v.String())
+	}
+	want := []string{
+		"keep entrypoint frontend off the cloud: runs on asus",
+		"forbid frontend on [cloud]: runs on asus",
+		"keep redis-cart on one node of [vm1]: runs on vm1, vm2",
+		"colocate cartservice with redis-cart: runs without redis-cart on vm3",
+		"spread productcatalogservice over at least 2 nodes: runs on 1 nodes",
+		"1 to 3 replicas of frontend: has 4 replicas",
+		"pin paymentservice to [edge]: runs on asus",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("violations:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if problem.checkConstraints(solution) {
+		t.Errorf("checkConstraints accepts a placement that breaks the rules")
+	}
+
+	for _, node := range topo.Nodes {
+		if problem.allowedOn("paymentservice", node) == node.IsCloud() {
+			t.Errorf("allowedOn(paymentservice, %s) = %v", node.Name, !node.IsCloud())
+		}
+	}
+	if problem.allowedOn("redis-cart", topo.Nodes[1]) {
+		t.Errorf("redis-cart allowed off its data node")
+	}
+}
+
+func TestPlacementRulesValidate(t *testing.T) {
+	topo := testTopology(t)
+	bad := []common.PlacementRule{
+		{Type: "pin", Service: "frontend"},
+		{Type: "forbid", Service: "nosuch", Nodes: []string{"vm1"}},
+		{Type: "forbid", Service: "frontend", Nodes: []string{"vm9"}},
+		{Type: "colocate", Service: "cartservice", With: "nosuch"},
+		{Type: "spread", Service: "frontend"},
+		{Type: "replicas", Service: "frontend", Min: 3, Max: 2},
+		{Type: "affinity", Service: "frontend"},
+	}
+	for _, rule := range bad {
+		rules := common.PlacementRules{Rules: []common.PlacementRule{rule}}
+		if err := rules.Validate(topo); err == nil {
+			t.Errorf("rule %+v accepted", rule)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"optimizer/analyzer"
+	"optimizer/common"
+	"os"
+)
+
+// Files lists the input documents an optimization run is loaded from.
+type Files struct {
+	App              string // preprocessed traces (GetTraceData output)
+	ServiceResources string
+	NodeResources    string
+	EdgeTimes        string
+	CloudTimes       string
+	DepICs           string // optional; no heatmap term when empty
+	SLOs             string // optional SLO targets per root operation
+	Cost             string // optional cost profile; no spend when empty
+	Power            string // optional power profile; no energy when empty
+	Placement        string // optional placement rules
+	Network          string // optional network profile; flat 50 ms per hop when empty
+
+	// NodeTimes maps a node name or node class to its own processing-time
+	// table; nodes without one use their tier's table and speed factor.
+	NodeTimes map[string]string
+}
+
+// DefaultFiles returns the file names used by the repository's sample data.
+// LoadProblem skips the optional ones among them where they do not exist.
+func DefaultFiles() Files {
+	return Files{
+		App:              "app.json",
+		ServiceResources: "resources_services.json",
+		NodeResources:    "resources_nodes.json",
+		EdgeTimes:        "processing_time_edge.json",
+		CloudTimes:       "processing_time_cloud.json",
+		DepICs:           "depICs.csv",
+		Network:          "network.json",
+		SLOs:             "slo.json",
+		Cost:             "cost.json",
+		Power:            "power.json",
+	}
+}
+
+// Problem bundles everything an optimizer needs to score deployments of one
+// scenario. Optimizers only read from it, so several problems can be solved
+// side by side in one process.
+type Problem struct {
+	Topology           *common.Topology
+	Traces             common.TraceData
+	ServiceConstraints common.ResourceConstraints
+	NodeConstraints    common.NodeConstraints
+	ProcessTimeEdge    map[string]map[string]int64            // [service][operation] µs
+	ProcessTimeCloud   map[string]map[string]int64            // [service][operation] µs
+	ProcessTimes       map[string]map[string]map[string]int64 // [node or class][service][operation] µs, overriding the tier tables
+	Network            *common.LatencyBandwidthMap            // links between nodes; empty means a flat delay per hop
+	Payloads           map[common.CallKey]int                 // bytes per call between services
+	DefaultPayload     int                                    // bytes of a call missing from Payloads
+	Heatmap            map[common.CallKey]float64             // DepIC between services
+	LoadFactor         float64                                // multiplies the arrival rates observed in Traces; 0 means 1
+	Latency            LatencyObjective                       // how predicted trace durations are reduced to one latency
+	SLO                SLOTargets                             // latency targets per root operation
+	Cost               *common.CostProfile                    // prices of nodes and egress; nil means no spend
+	SpendWeight        float64                                // weight of the spend per hour in the fitness
+	Power              *common.PowerProfile                   // power draw of nodes; nil means no energy
+	EnergyWeight       float64                                // weight of the energy in Wh per hour in the fitness
+	Placement          *common.PlacementRules                 // where services may run; nil means anywhere but the entrypoint on the cloud
+	Infeasible         string                                 // how deployments breaking a constraint are handled; empty means InfeasiblePenalty
+
+	// Derived from the fields above by index
+	heatmapKeys     []common.CallKey           // sorted keys of Heatmap
+	spanGraphs      []spanGraph                // call structure of each trace
+	rootOperations  []string                   // root operation of each trace
+	arrivalRate     map[string]float64         // requests/s per service in Traces
+	callRate        map[common.CallKey]float64 // calls/s between services in Traces
+	nodeTimes       []nodeTimes                // processing-time table of each node
+	meanServiceTime []map[string]float64       // µs per call per service on each node
+}
+
+// LoadProblem reads the inputs listed in files for the given topology.
+func LoadProblem(files Files, topo *common.Topology) (*Problem, error) {
+	p := &Problem{
+		Topology: topo,
+		Network:  common.NewLatencyBandwidthMap(),
+	}
+
+	if err := common.LoadJSONFile(files.App, &p.Traces); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", files.App, err)
+	}
+	if err := common.LoadJSONFile(files.ServiceResources, &p.ServiceConstraints); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", files.ServiceResources, err)
+	}
+	if err := common.LoadJSONFile(files.NodeResources, &p.NodeConstraints); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", files.NodeResources, err)
+	}
+	var missing []error
+	for _, service := range topo.Services {
+		if _, ok := p.ServiceConstraints[service]; !ok {
+			missing = append(missing, fmt.Errorf("service %s not found in %s", service, files.ServiceResources))
+		}
+	}
+	for _, node := range topo.NodeNames() {
+		if _, ok := p.NodeConstraints[node]; !ok {
+			missing = append(missing, fmt.Errorf("node %s not found in %s", node, files.NodeResources))
+		}
+	}
+	if err := errors.Join(missing...); err != nil {
+		return nil, err
+	}
+
+	if err := common.LoadJSONFile(files.EdgeTimes, &p.ProcessTimeEdge); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", files.EdgeTimes, err)
+	}
+	if err := common.LoadJSONFile(files.CloudTimes, &p.ProcessTimeCloud); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", files.CloudTimes, err)
+	}
+	for name, file := range files.NodeTimes {
+		var table map[string]map[string]int64
+		if err := common.LoadJSONFile(file, &table); err != nil {
+			return nil, fmt.Errorf("loading %s: %w", file, err)
+		}
+		if p.ProcessTimes == nil {
+			p.ProcessTimes = make(map[string]map[string]map[string]int64)
+		}
+		p.ProcessTimes[name] = table
+	}
+
+	// the optional profiles of the sample data are skipped where missing
+	defaults := DefaultFiles()
+	if wanted(files.Network, defaults.Network) {
+		profile, err := common.LoadNetworkProfile(files.Network)
+		if err != nil {
+			return nil, err
+		}
+		p.Network = profile.LatencyBandwidthMap()
+		p.Payloads = profile.PayloadBytes()
+		p.DefaultPayload = profile.DefaultPayload
+	}
+
+	if wanted(files.SLOs, defaults.SLOs) {
+		if err := common.LoadJSONFile(files.SLOs, &p.SLO); err != nil {
+			return nil, fmt.Errorf("loading %s: %w", files.SLOs, err)
+		}
+	}
+
+	if wanted(files.Cost, defaults.Cost) {
+		profile, err := common.LoadCostProfile(files.Cost)
+		if err != nil {
+			return nil, err
+		}
+		p.Cost = profile
+	}
+
+	if wanted(files.Power, defaults.Power) {
+		profile, err := common.LoadPowerProfile(files.Power)
+		if err != nil {
+			return nil, err
+		}
+		p.Power = profile
+	}
+
+	if files.Placement != "" {
+		rules, err := common.LoadPlacementRules(files.Placement, topo)
+		if err != nil {
+			return nil, err
+		}
+		p.Placement = rules
+	}
+
+	if wanted(files.DepICs, defaults.DepICs) {
+		heatmap, err := analyzer.LoadDepICsFromCSV(files.DepICs)
+		if err != nil {
+			return nil, err
+		}
+		p.Heatmap = heatmap
+	}
+	p.index()
+	if err := p.checkProcessTimes(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// wanted reports whether the optional input file name is to be loaded: it is
+// set and, if it is the default, exists. A file named explicitly is loaded
+// even if missing, so that the load fails.
+func wanted(name, defaultName string) bool {
+	if name == "" {
+		return false
+	}
+	if name == defaultName {
+		if _, err := os.Stat(name); errors.Is(err, fs.ErrNotExist) {
+			return false
+		}
+	}
+	return true
+}
+
+// index precomputes what evaluations derive from the nodes, traces,
+// processing times and heatmap. It has to run again after any of them changes.
+func (p *Problem) index() {
+	p.arrivalRate = arrivalRates(p.Traces)
+	p.callRate = callRates(p.Traces)
+	p.nodeTimes = nil
+	p.nodeTimes = p.allNodeTimes()
+	p.meanServiceTime = make([]map[string]float64, len(p.nodeTimes))
+	for i, times := range p.nodeTimes {
+		p.meanServiceTime[i] = meanServiceTimes(p.Traces, times)
+	}
+	p.heatmapKeys = sortedCallKeys(p.Heatmap)
+	p.spanGraphs = make([]spanGraph, len(p.Traces.Data))
+	p.rootOperations = make([]string, len(p.Traces.Data))
+	for i, trace := range p.Traces.Data {
+		p.spanGraphs[i] = newSpanGraph(trace)
+		p.rootOperations[i] = rootOperation(trace, p.spanGraphs[i])
+	}
+}
+
+// NewSolution returns a solution with every node and service set to zero
+// replicas.
+func (p *Problem) NewSolution() Solution {
+	solution := make(Solution)
+	for _, node := range p.Topology.NodeNames() {
+		solution[node] = make(map[string]int)
+		for _, service := range p.Topology.Services {
+			solution[node][service] = 0
+		}
+	}
+	return solution
+}
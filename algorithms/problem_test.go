@@ -0,0 +1,50 @@
+package algorithms
+
+import (
+	"optimizer/common"
+	"strings"
+	"testing"
+)
+
+func TestOptionalDefaultFiles(t *testing.T) {
+	defaults := DefaultFiles()
+	// the tests run in algorithms/, which has none of the sample files
+	if wanted(defaults.Cost, defaults.Cost) {
+		t.Errorf("missing default %s is loaded", defaults.Cost)
+	}
+	if !wanted("my_cost.json", defaults.Cost) {
+		t.Errorf("missing explicit file is skipped instead of failing the load")
+	}
+	if !wanted("../"+defaults.Cost, defaults.Cost) || wanted("", defaults.Cost) {
+		t.Errorf("wanted ignores the file name")
+	}
+
+	files := testFiles()
+	files.DepICs, files.Network, files.SLOs, files.Cost, files.Power = defaults.DepICs, defaults.Network, defaults.SLOs, defaults.Cost, defaults.Power
+	problem, err := LoadProblem(files, testTopology(t))
+	if err != nil {
+		t.Fatalf("LoadProblem without the optional sample files: %v", err)
+	}
+	if problem.Heatmap != nil || problem.Cost != nil || problem.Power != nil {
+		t.Errorf("profiles loaded from missing files")
+	}
+	files.Cost = "my_cost.json"
+	if _, err := LoadProblem(files, testTopology(t)); err == nil {
+		t.Errorf("missing explicit cost profile accepted")
+	}
+}
+
+func TestLoadProblemMissingResources(t *testing.T) {
+	topo := testTopology(t)
+	topo.Nodes = append(topo.Nodes, common.Node{Name: "vm4", Tier: common.TierEdge})
+	topo.Services = append(topo.Services, "adservice")
+	_, err := LoadProblem(testFiles(), topo)
+	if err == nil {
+		t.Fatal("LoadProblem accepted a node and a service without resources")
+	}
+	for _, name := range []string{"vm4", "adservice"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q does not name %s", err, name)
+		}
+	}
+}
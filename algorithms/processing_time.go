@@ -0,0 +1,96 @@
+package algorithms
+
+import (
+	"fmt"
+	"optimizer/common"
+)
+
+// nodeTimes is the processing-time table a node uses and the factor its
+// times are divided by.
+type nodeTimes struct {
+	table map[string]map[string]int64 // [service][operation] µs
+	speed float64
+}
+
+// of returns the processing time in µs of operation of service on the node.
+func (t nodeTimes) of(service, operation string) (float64, bool) {
+	v, ok := t.table[service][operation]
+	return float64(v) / t.speed, ok
+}
+
+// timesOf resolves the table of node: its own table in p.ProcessTimes, else
+// the table of its class, else the table of its tier. The node's speed
+// factor scales the tables it shares with other nodes.
+func (p *Problem) timesOf(node common.Node) nodeTimes {
+	if table, ok := p.ProcessTimes[node.Name]; ok {
+		return nodeTimes{table, 1}
+	}
+	if table, ok := p.ProcessTimes[node.ClassName()]; ok {
+		return nodeTimes{table, node.SpeedFactor()}
+	}
+	if node.Tier == common.TierCloud {
+		return nodeTimes{p.ProcessTimeCloud, node.SpeedFactor()}
+	}
+	return nodeTimes{p.ProcessTimeEdge, node.SpeedFactor()}
+}
+
+// allNodeTimes returns timesOf every node in topology order.
+func (p *Problem) allNodeTimes() []nodeTimes {
+	if p.nodeTimes != nil {
+		return p.nodeTimes
+	}
+	times := make([]nodeTimes, len(p.Topology.Nodes))
+	for i, node := range p.Topology.Nodes {
+		times[i] = p.timesOf(node)
+	}
+	return times
+}
+
+// checkProcessTimes reports the operations of the traces that a node's
+// table, or the edge table that services without replicas fall back to,
+// has no processing time for.
+func (p *Problem) checkProcessTimes() error {
+	seen := make(map[[2]string]bool)
+	for _, trace := range p.Traces.Data {
+		for _, span := range trace.Spans {
+			call := [2]string{span.ServiceName, span.OperationName}
+			if seen[call] {
+				continue
+			}
+			seen[call] = true
+			if _, ok := p.ProcessTimeEdge[call[0]][call[1]]; !ok {
+				return fmt.Errorf("no edge processing time for operation %s of %s", call[1], call[0])
+			}
+			if !p.Topology.HasService(call[0]) {
+				continue
+			}
+			for i, times := range p.nodeTimes {
+				if _, ok := times.of(call[0], call[1]); !ok {
+					return fmt.Errorf("no processing time for operation %s of %s on node %s", call[1], call[0], p.Topology.Nodes[i].Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// processTime returns the expected processing time in µs of a call to
+// operation of service, where ratios holds the share of the service's
+// replicas on each node. A service without replicas is timed as on the edge.
+func (p *Problem) processTime(times []nodeTimes, ratios []map[string]float64, service, operation string) float64 {
+	expected, placed := 0.0, 0.0
+	for i, t := range times {
+		share := ratios[i][service]
+		if share == 0 {
+			continue
+		}
+		v, _ := t.of(service, operation) // present, see checkProcessTimes
+		expected += share * v
+		placed += share
+	}
+	if placed > 0 {
+		return expected
+	}
+
+	return float64(p.ProcessTimeEdge[service][operation])
+}
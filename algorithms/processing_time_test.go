@@ -0,0 +1,79 @@
+package algorithms
+
+import (
+	"math"
+	"optimizer/common"
+	"testing"
+)
+
+func TestProcessTimePerNode(t *testing.T) {
+	problem := &Problem{
+		Topology: &common.Topology{
+			Nodes: []common.Node{
+				{Name: "edge1", Tier: common.TierEdge},
+				{Name: "edge2", Tier: common.TierEdge, Speed: 2},
+				{Name: "pi", Tier: common.TierEdge, Class: "arm", Speed: 0.5},
+				{Name: "cloud1", Tier: common.TierCloud},
+				{Name: "gpu", Tier: common.TierCloud},
+			},
+			Services: []string{"svc"},
+		},
+		ProcessTimeEdge:  map[string]map[string]int64{"svc": {"op": 1000}},
+		ProcessTimeCloud: map[string]map[string]int64{"svc": {"op": 400}},
+		ProcessTimes: map[string]map[string]map[string]int64{
+			"arm": {"svc": {"op": 3000}},
+			"gpu": {"svc": {"op": 100}},
+		},
+	}
+	problem.index()
+
+	// one replica on each node in turn
+	want := map[string]float64{
+		"edge1":  1000, // edge table
+		"edge2":  500,  // edge table, twice as fast
+		"pi":     6000, // class table, half as fast
+		"cloud1": 400,  // cloud table
+		"gpu":    100,  // own table, speed ignored
+	}
+	times := problem.allNodeTimes()
+	for node, w := range want {
+		solution := problem.NewSolution()
+		solution[node]["svc"] = 1
+		ratios := problem.nodeRatios(solution)
+		if got := problem.processTime(times, ratios, "svc", "op"); math.Abs(got-w) > 1e-9 {
+			t.Errorf("on %s: processTime = %v µs, want %v", node, got, w)
+		}
+	}
+
+	// replicas split 1:3 between edge1 and cloud1
+	solution := problem.NewSolution()
+	solution["edge1"]["svc"] = 1
+	solution["cloud1"]["svc"] = 3
+	if got, w := problem.processTime(times, problem.nodeRatios(solution), "svc", "op"), 0.25*1000+0.75*400; math.Abs(got-w) > 1e-9 {
+		t.Errorf("split: processTime = %v µs, want %v", got, w)
+	}
+}
+
+func TestCheckProcessTimes(t *testing.T) {
+	problem := &Problem{
+		Topology: &common.Topology{
+			Nodes:    []common.Node{{Name: "edge1", Tier: common.TierEdge}, {Name: "pi", Tier: common.TierEdge, Class: "arm"}},
+			Services: []string{"svc"},
+		},
+		Traces: common.TraceData{Data: []common.Trace{{Spans: []common.Span{
+			{ServiceName: "svc", OperationName: "op"},
+		}}}},
+		ProcessTimeEdge: map[string]map[string]int64{"svc": {"op": 1000}},
+		ProcessTimes:    map[string]map[string]map[string]int64{"arm": {"svc": {"other": 3000}}},
+	}
+	problem.index()
+	if err := problem.checkProcessTimes(); err == nil {
+		t.Error("checkProcessTimes accepted a class table without op")
+	}
+
+	problem.ProcessTimes["arm"]["svc"]["op"] = 3000
+	problem.index()
+	if err := problem.checkProcessTimes(); err != nil {
+		t.Errorf("checkProcessTimes: %v", err)
+	}
+}
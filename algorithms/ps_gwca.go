@@ -0,0 +1,133 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"optimizer/common"
+	"sync"
+)
+
+// PSGWCA runs a PSO and a GWO swarm in lockstep. After every iteration the
+// factory merges their Pareto fronts into the shared memory, from which both
+// swarms reseed their worst particle. Front members carry the objective
+// vector of Problem.Objectives.
+type PSGWCA struct {
+	Problem      *Problem
+	SharedMem    *common.SharedMemory
+	NumParticles int // per swarm
+	MaxIter      int
+	History      []float64        // best score of the merged front after each iteration
+	Contributed  []map[string]int // merged front members per swarm after each iteration
+	Rand         *rand.Rand       // seeds one source per swarm
+	Workers      int              // evaluation pool size of each swarm
+}
+
+func NewPSGWCA(problem *Problem, numParticles, maxIter int, rng *rand.Rand) *PSGWCA {
+	return &PSGWCA{
+		Problem:      problem,
+		SharedMem:    &common.SharedMemory{},
+		NumParticles: numParticles,
+		MaxIter:      maxIter,
+		Rand:         rng,
+		Workers:      defaultWorkers(),
+	}
+}
+
+// Optimize runs the hybrid and returns the member of the merged front with
+// the best scalar score, or an error if a swarm failed to score a deployment
+// or the front stayed empty. Once ctx is done the swarms skip their
+// remaining particles, and the run stops after the current iteration.
+func (ps *PSGWCA) Optimize(ctx context.Context) (common.Particle, error) {
+	var abDone sync.WaitGroup
+	// A failing swarm stops both through ctx and leaves its error as the cause
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+
+	// Channels for signaling
+	aDone := make(chan struct{}, 1)
+	bDone := make(chan struct{}, 1)
+	done := make(chan struct{}, 3)  // Buffered to avoid blocking
+	nextIter := make(chan struct{}) // Signal to start next iteration; closed to stop
+
+	var wg sync.WaitGroup
+	wg.Add(3)
+	// The swarms run concurrently, so each gets its own source
+	pso := NewPSO(ps.Problem, ps.SharedMem, ps.NumParticles, ps.MaxIter, rand.New(rand.NewSource(ps.Rand.Int63())))
+	gwo := NewGWO(ps.Problem, ps.SharedMem, ps.NumParticles, ps.MaxIter, rand.New(rand.NewSource(ps.Rand.Int63())))
+	pso.Workers, gwo.Workers = ps.Workers, ps.Workers
+	pso.stop, gwo.stop = stop, stop
+	factory := NewFactory(ps.SharedMem, ps.MaxIter)
+	// Add for PSO and GWO before any goroutine can reach abDone.Wait
+	abDone.Add(2)
+	go func() {
+		defer wg.Done()
+		pso.Cooperate(ctx, &abDone, aDone, done, nextIter)
+	}()
+	go func() {
+		defer wg.Done()
+		gwo.Cooperate(ctx, &abDone, bDone, done, nextIter)
+	}()
+	go func() {
+		defer wg.Done()
+		factory.Run(&abDone, aDone, bDone, done, nextIter)
+	}()
+
+	// Control iterations
+	for i := 0; i < ps.MaxIter; i++ {
+		// Wait for all three goroutines to signal completion of this iteration
+		for j := 0; j < 3; j++ {
+			<-done
+		}
+		if i == ps.MaxIter-1 {
+			break
+		}
+		if ctx.Err() != nil {
+			fmt.Printf("ps-gwca: stopped after iteration %d: %v\n", i, ctx.Err())
+			break
+		}
+		abDone.Add(2) // re-arm for the next iteration before releasing anyone
+		// Signal all goroutines to start the next iteration
+		for j := 0; j < 3; j++ {
+			nextIter <- struct{}{}
+		}
+	}
+
+	// All three wait on nextIter now; closing it ends their loops
+	close(nextIter)
+	wg.Wait()
+	close(aDone)
+	close(bDone)
+	close(done)
+
+	ps.History = factory.History
+	ps.Contributed = factory.Contributed
+	if err := context.Cause(ctx); err != nil && err != ctx.Err() {
+		return common.Particle{}, err
+	}
+	if len(ps.SharedMem.MergedFront) == 0 {
+		return common.Particle{}, fmt.Errorf("ps-gwca: empty Pareto front after %d iterations of %d particles", ps.MaxIter, ps.NumParticles)
+	}
+	return ps.SharedMem.MergedFront[0], nil
+}
+
+// randomSolutionForPS_GWCA places between one and ten replicas of every
+// service on random nodes, repaired in InfeasibleRepair mode.
+func (p *Problem) randomSolutionForPS_GWCA(rng *rand.Rand) map[string]map[string]int {
+	nodes := p.Topology.NodeNames()
+	solution := p.NewSolution()
+
+	for _, service := range p.Topology.Services {
+		// Generate random total instances for this service (1 to 10, adjust range as needed)
+		totalInstances := rng.Intn(10) + 1
+
+		// Randomly distribute the instances across nodes
+		for i := 0; i < totalInstances; i++ {
+			selectedNode := nodes[rng.Intn(len(nodes))]
+			solution[selectedNode][service]++
+		}
+	}
+
+	p.repairIfEnabled(solution)
+	return solution
+}
@@ -0,0 +1,47 @@
+package algorithms
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPSGWCA(t *testing.T) {
+	problem := testProblem(t)
+	ps := NewPSGWCA(problem, 6, 4, testRand())
+	best, err := ps.Optimize(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ps.History) != ps.MaxIter || len(ps.Contributed) != ps.MaxIter {
+		t.Fatalf("len(History) = %d, len(Contributed) = %d, want %d", len(ps.History), len(ps.Contributed), ps.MaxIter)
+	}
+	last := ps.Contributed[len(ps.Contributed)-1]
+	if got := last[SourcePSO] + last[SourceGWO]; got != len(ps.SharedMem.MergedFront) {
+		t.Errorf("swarms contributed %d members to a front of %d", got, len(ps.SharedMem.MergedFront))
+	}
+	nodes := make(map[string]bool)
+	for _, node := range problem.Topology.NodeNames() {
+		nodes[node] = true
+	}
+	for node := range best.BestSolution {
+		if !nodes[node] {
+			t.Errorf("best solution uses unknown node %s", node)
+		}
+	}
+
+	front := ps.SharedMem.MergedFront
+	for i, p := range front {
+		if len(p.Objectives) != NumObjectives {
+			t.Fatalf("member %d has %d objectives, want %d", i, len(p.Objectives), NumObjectives)
+		}
+		if p.Source != SourcePSO && p.Source != SourceGWO {
+			t.Errorf("member %d has source %q", i, p.Source)
+		}
+		for j, q := range front {
+			if dominates(q, p) {
+				t.Errorf("member %d of the merged front is dominated by member %d", i, j)
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"optimizer/common"
+	"sync"
+)
+
+const (
+	Omega = 0.45
+	C1    = 0.1
+)
+
+type PSO struct {
+	Problem      *Problem
+	SharedMem    *common.SharedMemory
+	Particles    []common.Particle
+	BestSolution map[string]map[string]int // gbest
+	BestScore    float64
+	ParetoFront  []common.Particle
+	Front        *[]common.Particle // shared memory slot ParetoFront is published to
+	NumParticles int
+	MaxIter      int
+	History      []float64 // gbest score after each iteration of a standalone run
+	Rand         *rand.Rand
+	Workers      int // particles scored concurrently
+
+	// stop ends the PS-GWCA run this swarm is part of with the error that
+	// failed it
+	stop context.CancelCauseFunc
+}
+
+// NewPSO builds a swarm of random particles; Optimize and Cooperate score
+// them with the swarm's Workers.
+func NewPSO(problem *Problem, sharedMem *common.SharedMemory, numParticles, maxIter int, rng *rand.Rand) *PSO {
+	particles := make([]common.Particle, numParticles)
+	for i := range particles {
+		particles[i] = common.Particle{
+			Solution:     problem.randomSolutionForPS_GWCA(rng),
+			BestSolution: problem.NewSolution(),
+			BestScore:    math.Inf(1), // scored by scoreNew
+		}
+	}
+	bestSolution := problem.NewSolution()
+	bestScore := math.Inf(1)
+	return &PSO{
+		Problem:      problem,
+		SharedMem:    sharedMem,
+		Front:        &sharedMem.PSOFront,
+		Particles:    particles,
+		BestSolution: bestSolution,
+		BestScore:    bestScore,
+		NumParticles: numParticles,
+		MaxIter:      maxIter,
+		Rand:         rng,
+		Workers:      defaultWorkers(),
+	}
+}
+
+// transferOperation moves containers randomly
+func transferOperation(p *common.Particle, problem *Problem, rng *rand.Rand) {
+	nodes := problem.Topology.NodeNames()
+	services := problem.Topology.Services
+	rowsToTransfer := selectRandomRows(rng, int(Omega*float64(len(services))), len(services))
+
+	for _, msIdx := range rowsToTransfer {
+		ms := services[msIdx] // get one ms to doing transfer
+		// Collect total containers for the microservice
+		totalContainers := 0
+		for _, pm := range nodes {
+			totalContainers += p.Solution[pm][ms]
+		}
+		// Clear current assignments
+		for _, pm := range nodes {
+			p.Solution[pm][ms] = 0
+		}
+		// Randomly redistribute containers
+		pmIDs := make([]string, 0, len(p.Solution))
+		for _, pm := range nodes {
+			pmIDs = append(pmIDs, pm)
+		}
+		for totalContainers > 0 {
+			newPM := pmIDs[rng.Intn(len(pmIDs))]
+			p.Solution[newPM][ms]++
+			totalContainers--
+		}
+	}
+	problem.repairIfEnabled(p.Solution)
+}
+
+// copyOperation copies rows from a reference solution
+func copyOperation(p *common.Particle, ref map[string]map[string]int, rows []int, problem *Problem) {
+	services := problem.Topology.Services
+	for _, msIdx := range rows {
+		ms := services[msIdx]
+		for pm := range p.Solution {
+			p.Solution[pm][ms] = ref[pm][ms]
+		}
+	}
+	problem.repairIfEnabled(p.Solution)
+}
+
+// selectRandomRows selects n random indices out of numRows
+func selectRandomRows(rng *rand.Rand, n, numRows int) []int {
+	rows := rng.Perm(numRows)
+	if n > numRows {
+		n = numRows
+	}
+	return rows[:n]
+}
+
+// Optimize runs the swarm on its own for up to MaxIter iterations and then
+// collects its Pareto front in ParetoFront. It returns early, keeping the
+// best solution found so far, once ctx is done.
+func (pso *PSO) Optimize(ctx context.Context) error {
+	if err := pso.scoreNew(ctx); err != nil {
+		return err
+	}
+	for i := 0; i < pso.MaxIter && ctx.Err() == nil; i++ {
+		if err := pso.iterate(ctx, i); err != nil {
+			return err
+		}
+		pso.History = append(pso.History, pso.BestScore)
+	}
+	pso.updateFront()
+	return nil
+}
+
+// Cooperate runs the swarm as one half of a PS-GWCA hybrid: every iteration
+// it publishes its Pareto front, reseeds its worst particle from the merged
+// front and hands over to the factory through the channels.
+func (pso *PSO) Cooperate(ctx context.Context, abDone *sync.WaitGroup, aDone chan<- struct{}, done chan<- struct{}, nextIter chan struct{}) {
+	nodes := pso.Problem.Topology.NodeNames()
+	if err := pso.scoreNew(ctx); err != nil {
+		pso.stop(err)
+	}
+	for i := 0; i < pso.MaxIter; i++ {
+		//*** Communicate with Shared Memory ***///
+		pso.updateFront()
+
+		pso.SharedMem.Lock()
+		*pso.Front = pso.ParetoFront
+		pso.SharedMem.Unlock()
+
+		pso.SharedMem.Lock()
+		if pso.SharedMem.Transform == 1 {
+			gwo := NewGWO(pso.Problem, pso.SharedMem, pso.NumParticles, pso.MaxIter-i, pso.Rand)
+			gwo.Front = pso.Front // keep publishing to this swarm's slot
+			gwo.Workers = pso.Workers
+			gwo.stop = pso.stop
+			for j := 0; j < pso.NumParticles/2; j++ {
+				gwo.Particles[j] = pso.Particles[j]
+			}
+			pso.SharedMem.Transform = 0
+			pso.SharedMem.Unlock()
+			gwo.Cooperate(ctx, abDone, aDone, done, nextIter)
+			return
+		}
+		pso.SharedMem.Unlock()
+
+		pso.SharedMem.RLock()
+		newFront := pso.SharedMem.MergedFront
+		pso.SharedMem.RUnlock()
+
+		if len(newFront) > 0 {
+			worstIdx := 0
+			worstScore := -math.Inf(1)
+			for j, p := range pso.Particles {
+				if p.BestScore > worstScore {
+					worstScore = p.BestScore
+					worstIdx = j
+				}
+			}
+			randIdx := pso.Rand.Intn(len(newFront))
+			pso.Particles[worstIdx].Solution = make(map[string]map[string]int)
+			for _, pm := range nodes {
+				pso.Particles[worstIdx].Solution[pm] = make(map[string]int)
+			}
+			common.CopySolution(pso.Particles[worstIdx].Solution, newFront[randIdx].Solution)
+			score, err := pso.Problem.Evaluate(pso.Particles[worstIdx].Solution)
+			if err != nil {
+				pso.stop(err)
+			}
+			pso.Particles[worstIdx].BestScore = score
+		}
+		aDone <- struct{}{} // Signal that critical section A is done
+		abDone.Done()       // Signal that A is done for C to proceed
+
+		//*** Original PSO Part ***///
+		if err := pso.iterate(ctx, i); err != nil {
+			pso.stop(err)
+		}
+		// Signal completion of this iteration
+		done <- struct{}{}
+		// Wait for the next iteration signal
+		if _, ok := <-nextIter; !ok {
+			break
+		}
+	}
+}
+
+// scoreNew scores the particles not scored yet and updates gbest.
+func (pso *PSO) scoreNew(ctx context.Context) error {
+	if err := pso.Problem.scoreNew(ctx, pso.Particles, pso.Workers); err != nil {
+		return err
+	}
+	for _, p := range pso.Particles {
+		if p.BestScore < pso.BestScore {
+			pso.BestScore = p.BestScore
+			common.CopySolution(pso.BestSolution, p.BestSolution)
+		}
+	}
+	return nil
+}
+
+// updateFront rebuilds ParetoFront from the current particles.
+func (pso *PSO) updateFront() {
+	pso.ParetoFront = []common.Particle{}
+	for _, m := range pso.Problem.frontMembers(pso.Particles, SourcePSO, pso.Workers) {
+		pso.ParetoFront = updateParetoFront(pso.ParetoFront, m)
+	}
+}
+
+// iterate moves every particle once and updates pbest and gbest. The moves
+// draw from pso.Rand in a fixed order, so they stay sequential; the particles
+// are then scored concurrently.
+func (pso *PSO) iterate(ctx context.Context, i int) error {
+	services := pso.Problem.Topology.Services
+	var solutions []map[string]map[string]int
+	for j := range pso.Particles {
+		if ctx.Err() != nil {
+			break // finish the iteration with the particles moved so far
+		}
+		transferOperation(&pso.Particles[j], pso.Problem, pso.Rand)
+		pbestRows := selectRandomRows(pso.Rand, int(C1*float64(len(services))), len(services))
+		copyOperation(&pso.Particles[j], pso.Particles[j].BestSolution, pbestRows, pso.Problem)
+		solutions = append(solutions, pso.Particles[j].Solution)
+	}
+	scores, err := pso.Problem.evaluateAll(ctx, solutions, pso.Workers)
+	if stoppedBy(ctx, err) {
+		fmt.Printf("pso: stopped before iteration %d was scored: %v\n", i, err)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for j, score := range scores {
+		// Update pbest
+		if score < pso.Particles[j].BestScore {
+			pso.Particles[j].BestScore = score
+			pso.Particles[j].BestSolution = make(map[string]map[string]int)
+
+			// Initialize nested maps before copying
+			for pm := range pso.Particles[j].Solution {
+				pso.Particles[j].BestSolution[pm] = make(map[string]int)
+			}
+
+			common.CopySolution(pso.Particles[j].BestSolution, pso.Particles[j].Solution)
+		}
+
+		// Update gbest
+		if pso.Particles[j].BestScore < pso.BestScore {
+			pso.BestScore = pso.Particles[j].BestScore
+			pso.BestSolution = make(map[string]map[string]int)
+
+			// Initialize nested maps before copying
+			for pm := range pso.Particles[j].BestSolution {
+				pso.BestSolution[pm] = make(map[string]int)
+			}
+
+			common.CopySolution(pso.BestSolution, pso.Particles[j].BestSolution)
+		}
+	}
+	return nil
+}
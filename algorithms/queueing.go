@@ -0,0 +1,146 @@
+package algorithms
+
+import (
+	"optimizer/common"
+)
+
+// saturatedWait is the wait in µs charged per call to a service whose
+// replicas on a node receive more requests than they can serve.
+const saturatedWait = 10 * 1000 * 1000
+
+// arrivalRates returns the requests per second each service received in
+// traces: the spans entered from another service (or from outside) over the
+// time the traces cover.
+func arrivalRates(traces common.TraceData) map[string]float64 {
+	rates := make(map[string]float64)
+	for _, trace := range traces.Data {
+		for _, span := range trace.Spans {
+			if span.ParentService != span.ServiceName {
+				rates[span.ServiceName]++
+			}
+		}
+	}
+	window := traceWindow(traces)
+	for service := range rates {
+		if window > 0 {
+			rates[service] /= window
+		} else {
+			rates[service] = 0
+		}
+	}
+	return rates
+}
+
+// traceWindow returns the seconds from the first span of traces starting to
+// the last one ending.
+func traceWindow(traces common.TraceData) float64 {
+	var first, last int64
+	for _, trace := range traces.Data {
+		for _, span := range trace.Spans {
+			if first == 0 || span.StartTime < first {
+				first = span.StartTime
+			}
+			if end := span.StartTime + span.Duration; end > last {
+				last = end
+			}
+		}
+	}
+	return float64(last-first) / 1e6
+}
+
+// meanServiceTimes returns, per service, the processing time in µs of its
+// operations on a node with times, weighted by how often each operation is
+// called in traces.
+func meanServiceTimes(traces common.TraceData, times nodeTimes) map[string]float64 {
+	total := make(map[string]float64)
+	calls := make(map[string]int)
+	for _, trace := range traces.Data {
+		for _, span := range trace.Spans {
+			if t, ok := times.of(span.ServiceName, span.OperationName); ok {
+				total[span.ServiceName] += t
+				calls[span.ServiceName]++
+			}
+		}
+	}
+	means := make(map[string]float64, len(total))
+	for service, t := range total {
+		means[service] = t / float64(calls[service])
+	}
+	return means
+}
+
+// queueWaits returns, per service, the expected time in µs a call waits for
+// a free replica under solution. The replicas of a service on one node form
+// an M/M/c queue; calls are spread over nodes in proportion to their
+// replicas, and the arrival rate is the observed one times p.LoadFactor.
+func (p *Problem) queueWaits(solution map[string]map[string]int) map[string]float64 {
+	loadFactor := p.loadFactor()
+
+	waits := make(map[string]float64, len(p.Topology.Services))
+	for _, service := range p.Topology.Services {
+		lambda := p.arrivalRate[service] * loadFactor
+		replicas := 0
+		for _, node := range p.Topology.NodeNames() {
+			replicas += solution[node][service]
+		}
+		if lambda == 0 || replicas == 0 {
+			continue
+		}
+		for i, node := range p.Topology.NodeNames() {
+			c := solution[node][service]
+			if c == 0 {
+				continue
+			}
+			share := float64(c) / float64(replicas)
+			waits[service] += share * mmcWait(lambda*share, p.serviceRate(service, i), c)
+		}
+	}
+	return waits
+}
+
+// loadFactor returns p.LoadFactor, or 1 if it is unset.
+func (p *Problem) loadFactor() float64 {
+	if p.LoadFactor == 0 {
+		return 1
+	}
+	return p.LoadFactor
+}
+
+// serviceRate returns the requests per second one replica of service on the
+// i-th node can serve, or 0 if its processing time is unknown.
+func (p *Problem) serviceRate(service string, i int) float64 {
+	var mean float64
+	if p.meanServiceTime != nil {
+		mean = p.meanServiceTime[i][service]
+	} else {
+		mean = meanServiceTimes(p.Traces, p.timesOf(p.Topology.Nodes[i]))[service]
+	}
+	if mean <= 0 {
+		return 0
+	}
+	return 1e6 / mean
+}
+
+// mmcWait returns the expected wait in µs of an M/M/c queue with arrival rate
+// lambda and per-server rate mu (both per second), using the Erlang C
+// formula. Queues at or beyond saturation wait saturatedWait.
+func mmcWait(lambda, mu float64, c int) float64 {
+	if lambda <= 0 || mu <= 0 {
+		return 0
+	}
+	a := lambda / mu // offered load
+	rho := a / float64(c)
+	if rho >= 1 {
+		return saturatedWait
+	}
+
+	// sum of a^k/k! for k < c; term ends as a^c/c!
+	sum, term := 0.0, 1.0
+	for k := 0; k < c; k++ {
+		sum += term
+		term *= a / float64(k+1)
+	}
+	last := term / (1 - rho)
+	erlangC := last / (sum + last) // probability that a call has to wait
+	return erlangC / (float64(c)*mu - lambda) * 1e6
+}
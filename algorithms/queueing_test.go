@@ -0,0 +1,44 @@
+package algorithms
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMMCWait(t *testing.T) {
+	tests := []struct {
+		lambda, mu float64
+		c          int
+		want       float64 // µs
+	}{
+		{1, 2, 1, 0.5e6},   // M/M/1: rho/(mu-lambda)
+		{2, 2, 2, 1e6 / 6}, // Erlang C = 1/3, over 2*2-2
+		{4, 2, 2, saturatedWait},
+		{0, 2, 1, 0},
+	}
+	for _, tt := range tests {
+		if got := mmcWait(tt.lambda, tt.mu, tt.c); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("mmcWait(%v, %v, %d) = %v, want %v", tt.lambda, tt.mu, tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestQueueWaitsRewardReplicas(t *testing.T) {
+	problem := testProblem(t)
+	problem.LoadFactor = 200
+
+	solution := problem.NewSolution()
+	for _, service := range problem.Topology.Services {
+		solution["vm1"][service] = 1
+	}
+	before := problem.queueWaits(solution)["productcatalogservice"]
+	solution["vm2"]["productcatalogservice"] = 2
+	after := problem.queueWaits(solution)["productcatalogservice"]
+
+	if before <= 0 {
+		t.Fatalf("one replica under 200x load does not queue")
+	}
+	if after >= before {
+		t.Errorf("wait with 3 replicas %v µs is not below the wait with 1 replica %v µs", after, before)
+	}
+}
@@ -0,0 +1,168 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"optimizer/common"
+)
+
+// Ways of handling deployments that break a constraint.
+const (
+	InfeasiblePenalty = "penalty" // score infeasiblePenalty
+	InfeasibleGraded  = "graded"  // score the fitness plus a penalty growing with the violation
+	InfeasibleRepair  = "repair"  // repair deployments as the optimizers build them
+)
+
+// gradedPenalty is what the graded mode adds to infeasiblePenalty per unit
+// of violation: per broken placement rule and per node capacity of a
+// resource overcommitted.
+const gradedPenalty = 10000.0
+
+// SetInfeasibleMode selects how p treats deployments that break a
+// constraint: InfeasiblePenalty, InfeasibleGraded or InfeasibleRepair.
+func (p *Problem) SetInfeasibleMode(mode string) error {
+	switch mode {
+	case "", InfeasiblePenalty, InfeasibleGraded, InfeasibleRepair:
+		p.Infeasible = mode
+		return nil
+	}
+	return fmt.Errorf("unknown infeasible mode %q (want %s, %s or %s)", mode, InfeasiblePenalty, InfeasibleGraded, InfeasibleRepair)
+}
+
+// infeasibleScore returns the score of a deployment that breaks a
+// constraint. Infeasible deployments rank after every feasible one, whose
+// scores feasibleScore caps at infeasiblePenalty; in graded mode they rank
+// among themselves by how far they are from feasible, whatever their
+// latency.
+func (p *Problem) infeasibleScore(solution map[string]map[string]int) float64 {
+	if p.Infeasible != InfeasibleGraded {
+		return infeasiblePenalty
+	}
+	return infeasiblePenalty + gradedPenalty*p.violation(solution)
+}
+
+// feasibleScore caps the score of a feasible deployment at
+// infeasiblePenalty, so no feasible deployment ranks after an infeasible
+// one, however saturated its queues.
+func feasibleScore(score float64) float64 {
+	return min(score, infeasiblePenalty)
+}
+
+// violation measures how far solution is from feasible: the number of
+// placement rules it breaks plus, for every resource a node is asked too
+// much of, the excess as a share of the node's capacity.
+func (p *Problem) violation(solution map[string]map[string]int) float64 {
+	amount := float64(len(p.placementViolations(solution)))
+	for _, e := range p.Feasibility(solution) {
+		amount += float64(e.Over()) / float64(max(e.Capacity, 1))
+	}
+	return amount
+}
+
+// repairIfEnabled repairs solution in place when p is in InfeasibleRepair
+// mode.
+func (p *Problem) repairIfEnabled(solution map[string]map[string]int) {
+	if p.Infeasible == InfeasibleRepair {
+		p.repair(solution)
+	}
+}
+
+// repair moves replicas of solution off the nodes their service may not run
+// on and off nodes they ask too much of, largest CPU request first. A
+// replica goes to the allowed node with the most free CPU that can take it,
+// or is removed if there is none. Nodes are tried in topology order, so a
+// repair is deterministic.
+func (p *Problem) repair(solution map[string]map[string]int) {
+	nodes := p.Topology.Nodes
+	free := make([]map[string]int, len(nodes)) // nil for unconstrained nodes
+	for i, node := range nodes {
+		free[i], _ = p.free(solution, node.Name)
+	}
+	freeCPU := func(i int) int {
+		if free[i] == nil {
+			return math.MaxInt
+		}
+		return free[i][ResourceCPU]
+	}
+	take := func(i int, req map[string]int, sign int) {
+		if free[i] == nil {
+			return
+		}
+		for resource, amount := range req {
+			free[i][resource] -= sign * amount
+		}
+	}
+	overcommitted := func(i int) bool {
+		for _, left := range free[i] {
+			if left < 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	move := func(from int, service string) {
+		req := p.requests(service)
+		solution[nodes[from].Name][service]--
+		take(from, req, -1)
+
+		to := -1
+		for i, node := range nodes {
+			if i == from || !p.allowedOn(service, node) || (free[i] != nil && fits(req, free[i]) == 0) {
+				continue
+			}
+			if to < 0 || freeCPU(i) > freeCPU(to) {
+				to = i
+			}
+		}
+		if to >= 0 {
+			solution[nodes[to].Name][service]++
+			take(to, req, 1)
+		}
+	}
+
+	for i, node := range nodes {
+		for _, service := range p.Topology.Services {
+			for solution[node.Name][service] > 0 && !p.allowedOn(service, node) {
+				move(i, service)
+			}
+		}
+	}
+	for i, node := range nodes {
+		for overcommitted(i) {
+			service := p.largestRequest(solution, node, free[i])
+			if service == "" {
+				break
+			}
+			move(i, service)
+		}
+	}
+}
+
+// largestRequest returns, of the services with replicas on node that
+// request a resource overcommitted in free, the one requesting the most CPU,
+// then memory; ties go to the first in p.Topology.Services.
+func (p *Problem) largestRequest(solution map[string]map[string]int, node common.Node, free map[string]int) string {
+	var largest string
+	var req common.Constraints
+	for _, service := range p.Topology.Services {
+		if solution[node.Name][service] == 0 || !requestsAny(p.requests(service), free) {
+			continue
+		}
+		r := p.ServiceConstraints[service]
+		if largest == "" || r.CPU > req.CPU || (r.CPU == req.CPU && r.Memory > req.Memory) {
+			largest, req = service, r
+		}
+	}
+	return largest
+}
+
+// requestsAny reports whether req asks for a resource overcommitted in free.
+func requestsAny(req, free map[string]int) bool {
+	for resource, left := range free {
+		if left < 0 && req[resource] > 0 {
+			return true
+		}
+	}
+	return false
+}
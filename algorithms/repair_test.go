@@ -0,0 +1,106 @@
+package algorithms
+
+import (
+	"optimizer/common"
+	"testing"
+)
+
+func repairProblem() *Problem {
+	return &Problem{
+		Topology: &common.Topology{
+			Entrypoint: "front",
+			Nodes: []common.Node{
+				{Name: "edge1", Tier: common.TierEdge},
+				{Name: "edge2", Tier: common.TierEdge},
+				{Name: "cloud1", Tier: common.TierCloud},
+			},
+			Services: []string{"front", "big", "small"},
+		},
+		ServiceConstraints: common.ResourceConstraints{
+			"front": {CPU: 100, Memory: 10},
+			"big":   {CPU: 400, Memory: 10},
+			"small": {CPU: 100, Memory: 10},
+		},
+		NodeConstraints: common.NodeConstraints{
+			"edge1":  {CPU: 1000, Memory: 100},
+			"edge2":  {CPU: 500, Memory: 100},
+			"cloud1": {CPU: 500, Memory: 100},
+		},
+	}
+}
+
+func TestRepair(t *testing.T) {
+	problem := repairProblem()
+	solution := problem.NewSolution()
+	solution["edge1"]["big"] = 3   // 1200 of 1000 CPU
+	solution["edge1"]["small"] = 1 // 1300 of 1000 CPU
+	solution["cloud1"]["front"] = 1
+	problem.repair(solution)
+
+	if len(problem.placementViolations(solution)) != 0 || solution["edge2"]["front"] != 1 {
+		t.Errorf("entrypoint not moved off the cloud: %v", solution)
+	}
+	if excess := problem.Feasibility(solution); len(excess) != 0 {
+		t.Errorf("still overcommitted after repair: %v", excess)
+	}
+	// one big replica moves to the node with the most free CPU
+	if solution["edge1"]["big"] != 2 || solution["cloud1"]["big"] != 1 || solution["edge1"]["small"] != 1 {
+		t.Errorf("repair moved the wrong replicas: %v", solution)
+	}
+
+	// six big replicas do not fit anywhere; two are dropped
+	solution["edge1"]["big"] = 4
+	solution["edge2"]["big"] = 1
+	problem.repair(solution)
+	total := 0
+	for _, node := range problem.Topology.NodeNames() {
+		total += solution[node]["big"]
+	}
+	if total != 4 {
+		t.Errorf("%d big replicas after repair, want 4: %v", total, solution)
+	}
+}
+
+func TestGradedPenalty(t *testing.T) {
+	problem := repairProblem()
+	if err := problem.SetInfeasibleMode("lenient"); err == nil {
+		t.Fatalf("unknown mode accepted")
+	}
+	if err := problem.SetInfeasibleMode(InfeasibleGraded); err != nil {
+		t.Fatal(err)
+	}
+
+	solution := problem.NewSolution()
+	solution["edge1"]["big"] = 3
+	slightly := problem.violation(solution)
+	solution["edge1"]["big"] = 5
+	badly := problem.violation(solution)
+	if slightly <= 0 || badly <= slightly {
+		t.Errorf("violation with 3 and 5 big replicas on edge1 = %v, %v; want growing", slightly, badly)
+	}
+
+	solution["edge1"]["big"] = 1
+	if v := problem.violation(solution); v != 0 {
+		t.Errorf("violation of a feasible placement = %v", v)
+	}
+
+	// a feasible deployment ranks first however saturated its queues
+	solution["edge1"]["big"] = 3
+	if infeasible, feasible := problem.infeasibleScore(solution), feasibleScore(1e12); infeasible <= feasible {
+		t.Errorf("infeasible score %v not above the feasible score %v", infeasible, feasible)
+	}
+}
+
+func TestGradedFrontMember(t *testing.T) {
+	problem := testProblem(t)
+	if err := problem.SetInfeasibleMode(InfeasibleGraded); err != nil {
+		t.Fatal(err)
+	}
+	solution := problem.NewSolution()
+	solution[problem.Topology.CloudNodes()[0]][problem.Topology.Entrypoint] = 1
+
+	member := problem.frontMember(common.Particle{Solution: solution}, SourcePSO)
+	if member.Feasible || member.BestScore != problem.infeasibleScore(solution) || member.BestScore <= infeasiblePenalty {
+		t.Errorf("front member scored %v, want the graded score %v", member.BestScore, problem.infeasibleScore(solution))
+	}
+}
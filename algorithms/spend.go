@@ -0,0 +1,72 @@
+package algorithms
+
+import (
+	"optimizer/common"
+)
+
+// callRates returns the calls per second between every pair of services in
+// traces, over the time the traces cover.
+func callRates(traces common.TraceData) map[common.CallKey]float64 {
+	rates := make(map[common.CallKey]float64)
+	for _, trace := range traces.Data {
+		for _, span := range trace.Spans {
+			if span.ParentService == span.ServiceName || span.ParentService == "" || span.ParentService == "none" {
+				continue
+			}
+			rates[common.CallKey{From: span.ParentService, To: span.ServiceName}]++
+		}
+	}
+	window := traceWindow(traces)
+	for key := range rates {
+		if window > 0 {
+			rates[key] /= window
+		} else {
+			rates[key] = 0
+		}
+	}
+	return rates
+}
+
+// spend returns the price per hour of running solution under p.Cost: the
+// CPU and memory its replicas request on each node, plus the egress of the
+// calls between services placed on different nodes, priced by the tiers of
+// the nodes, at the call rates observed in the traces times p.LoadFactor.
+// It is 0 without a cost profile.
+func (p *Problem) spend(solution map[string]map[string]int) float64 {
+	if p.Cost == nil {
+		return 0
+	}
+
+	total := 0.0
+	for _, node := range p.Topology.Nodes {
+		price := p.Cost.PriceOf(node)
+		for _, service := range p.Topology.Services {
+			replicas := float64(solution[node.Name][service])
+			total += replicas * (price.CPU*float64(p.ServiceConstraints[service].CPU) +
+				price.Memory*float64(p.ServiceConstraints[service].Memory))
+		}
+	}
+
+	if len(p.Cost.Egress) == 0 {
+		return total
+	}
+	rates := p.callRate
+	if rates == nil {
+		rates = callRates(p.Traces)
+	}
+	ratios := p.nodeRatios(solution)
+	nodes := p.Topology.Nodes
+	for _, key := range sortedCallKeys(rates) { // fixed order keeps the float sum reproducible
+		gbPerHour := rates[key] * p.loadFactor() * 3600 * float64(p.payloadBytes(key.From, key.To)) / 1e9
+		for a := range nodes {
+			for b := range nodes {
+				weight := ratios[a][key.From] * ratios[b][key.To]
+				if a == b || weight == 0 {
+					continue
+				}
+				total += weight * gbPerHour * p.Cost.EgressPerGB(nodes[a].Tier, nodes[b].Tier)
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,54 @@
+package algorithms
+
+import (
+	"math"
+	"optimizer/common"
+	"testing"
+)
+
+func TestSpend(t *testing.T) {
+	problem := &Problem{
+		Topology: &common.Topology{
+			Nodes: []common.Node{
+				{Name: "edge1", Tier: common.TierEdge},
+				{Name: "cloud1", Tier: common.TierCloud},
+			},
+			Services: []string{"a", "b"},
+		},
+		ServiceConstraints: common.ResourceConstraints{
+			"a": {CPU: 100, Memory: 10},
+			"b": {CPU: 200, Memory: 20},
+		},
+		Cost: &common.CostProfile{
+			Nodes: map[string]common.Price{
+				"edge":   {CPU: 0.001, Memory: 0.01},
+				"cloud1": {CPU: 0.002, Memory: 0.02},
+			},
+			Egress: []common.Egress{{From: common.TierEdge, To: common.TierCloud, PerGB: 10}},
+		},
+		DefaultPayload: 1e6,
+		// a calls b once per second
+		callRate: map[common.CallKey]float64{{From: "a", To: "b"}: 1},
+	}
+
+	solution := problem.NewSolution()
+	solution["edge1"]["a"] = 1
+	solution["edge1"]["b"] = 1
+	together := (0.1 + 0.1) + (0.2 + 0.2) // CPU and memory on the edge
+	if got := problem.spend(solution); math.Abs(got-together) > 1e-9 {
+		t.Errorf("both on the edge: spend = %v, want %v", got, together)
+	}
+
+	solution["edge1"]["b"] = 0
+	solution["cloud1"]["b"] = 1
+	egress := 3600 * 1e6 / 1e9 * 10.0 // 3.6 GB an hour at 10 per GB
+	split := (0.1 + 0.1) + (0.4 + 0.4) + egress
+	if got := problem.spend(solution); math.Abs(got-split) > 1e-9 {
+		t.Errorf("b on the cloud: spend = %v, want %v", got, split)
+	}
+
+	problem.Cost = nil
+	if got := problem.spend(solution); got != 0 {
+		t.Errorf("without a cost profile: spend = %v, want 0", got)
+	}
+}
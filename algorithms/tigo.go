@@ -1,21 +1,30 @@
 package algorithms
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"math/rand"
 	"optimizer/common"
-	"time"
 )
 
 type Solution map[string]map[string]int // Solution[node][service] = <replica>
 
-// var traceData TraceData
+// TIGO searches deployments by repeatedly offloading spans of a trace to the
+// cloud and then filling the edge nodes with the replicas each service needs.
+type TIGO struct {
+	Problem *Problem
+	BS      int       // Branch Search Size
+	History []float64 // best score among the candidates of each round
+	Rand    *rand.Rand
+}
 
-func InitTIGO() {
-	common.LoadJSONFile("app.json", &traceData)
-	common.LoadJSONFile("resources_services.json", &serviceConstraints)
-	common.LoadJSONFile("resources_common.Nodes.json", &nodeConstraints)
-	common.LoadJSONFile("processing_time_edge.json", &processTimeMap)
-	common.LoadJSONFile("processing_time_cloud.json", &processTimeCloudMap)
+func NewTIGO(problem *Problem, BS int, rng *rand.Rand) *TIGO {
+	return &TIGO{
+		Problem: problem,
+		BS:      BS,
+		Rand:    rng,
+	}
 }
 
 // 初始化路由
@@ -26,10 +35,6 @@ func initRouting() ([]int, int) {
 
 func CopySolution(original Solution) Solution {
 	copy := make(Solution)
-	for _, node := range common.Nodes {
-		copy[node] = make(map[string]int)
-	}
-
 	for outerKey, innerMap := range original {
 		innerCopy := make(map[string]int)
 		for innerKey, value := range innerMap {
@@ -41,32 +46,46 @@ func CopySolution(original Solution) Solution {
 }
 
 // 雲端執行方案改進
-func cloudExecSchemeImprove(solution Solution, BS int) []Solution {
-	prevT := evaluate(solution)
+// Candidate offloading schemes are placed on the first cloud node of the p.Topology.
+// Once ctx is done it returns the candidates found so far.
+func (tigo *TIGO) cloudExecSchemeImprove(ctx context.Context, solution Solution, BS int) ([]Solution, error) {
+	p := tigo.Problem
+	cloud := p.Topology.CloudNodes()[0]
+	prevT, err := p.Evaluate(solution)
+	if err != nil {
+		return nil, err
+	}
 	cands := []Solution{}
-	for i := range traceData.Data {
+	for i := range p.Traces.Data {
 		// var predictDuration float64 = 0
 
-		L := len(traceData.Data[i].Spans)
+		L := len(p.Traces.Data[i].Spans)
 		for j := 0; j < L; j++ {
+			if ctx.Err() != nil {
+				return truncate(cands, BS), nil
+			}
 			for k := j; k < L; k++ {
 				// build a solution (tempSolution)
 				tempSolution := CopySolution(solution)
 				onCloudServices := make([]string, 0)
 				for t := j; t <= k; t++ {
-					onCloudServices = append(onCloudServices, traceData.Data[i].Spans[t].ServiceName)
+					onCloudServices = append(onCloudServices, p.Traces.Data[i].Spans[t].ServiceName)
 				}
 
-				for _, service := range common.Services {
-					tempSolution["asus"][service] = 0
+				for _, service := range p.Topology.Services {
+					tempSolution[cloud][service] = 0
 				}
 
 				for _, service := range onCloudServices {
-					tempSolution["asus"][service] = 1
+					tempSolution[cloud][service] = 1
 				}
 
 				// evaluate a solution
-				tempT := evaluate(tempSolution)
+				p.repairIfEnabled(tempSolution)
+				tempT, err := p.Evaluate(tempSolution)
+				if err != nil {
+					return nil, err
+				}
 				if tempT < prevT {
 					cands = append(cands, tempSolution)
 				}
@@ -74,19 +93,25 @@ func cloudExecSchemeImprove(solution Solution, BS int) []Solution {
 		}
 	}
 
-	if len(cands) < BS {
-		return cands
+	return truncate(cands, BS), nil
+}
+
+// truncate returns at most the first n solutions.
+func truncate(sls []Solution, n int) []Solution {
+	if len(sls) < n {
+		return sls
 	}
-	return cands[0:BS]
+	return sls[0:n]
 }
 
-func calculateNeeded(service string) int {
+func (tigo *TIGO) calculateNeeded(service string) int {
+	p := tigo.Problem
 	totalNumber := 0
 
-	for i := range traceData.Data {
-		L := len(traceData.Data[i].Spans)
+	for i := range p.Traces.Data {
+		L := len(p.Traces.Data[i].Spans)
 		for j := 0; j < L; j++ {
-			if traceData.Data[i].Spans[j].ServiceName == service {
+			if p.Traces.Data[i].Spans[j].ServiceName == service {
 				totalNumber++
 			}
 		}
@@ -96,48 +121,50 @@ func calculateNeeded(service string) int {
 	return totalNumber / 50
 }
 
-func bestServer(solution Solution, service string) (string, int64) {
-	edgeNodes := []string{"vm1", "vm2", "vm3"}
-
-	remaining := make(map[string]int64)
-	for _, e := range edgeNodes {
-		remaining[e] = int64(nodeConstraints[e].CPU)
-	}
-
-	for _, node := range common.Nodes {
-		for _, service := range common.Services {
-			remaining[node] += -(int64(solution[node][service]) * int64(serviceConstraints[service].CPU))
-		}
-	}
+// bestServer returns the edge node that may run service and has room for
+// the most replicas of it, counting every resource it requests, and how
+// many replicas fit there.
+func (tigo *TIGO) bestServer(solution Solution, service string) (string, int64) {
+	p := tigo.Problem
+	req := p.requests(service)
 
+	// walk the nodes in topology order so that ties always go the same way
 	var maxKey string
 	maxValue := int64(0)
-	for key, value := range remaining {
-		if value > maxValue {
+	for _, node := range p.Topology.Nodes {
+		if node.Tier != common.TierEdge || !p.allowedOn(service, node) {
+			continue
+		}
+		free, ok := p.free(solution, node.Name)
+		if !ok {
+			continue // no known capacity
+		}
+		if value := int64(fits(req, free)); value > maxValue {
 			maxValue = value
-			maxKey = key
+			maxKey = node.Name
 		}
 	}
-	return maxKey, maxValue / int64(serviceConstraints[service].CPU)
+	return maxKey, maxValue
 }
 
 // 邊緣替換策略
-func edgeReplacement(solution Solution) Solution {
+func (tigo *TIGO) edgeReplacement(solution Solution) Solution {
+	p := tigo.Problem
 	retSolution := CopySolution(solution)
 
-	for _, service := range common.Services {
-		needed := calculateNeeded(service) // TODO: calculateNeeded
+	for _, service := range p.Topology.Services {
+		needed := tigo.calculateNeeded(service) // TODO: calculateNeeded
 		deployed := 0
 		var prevBestS string
 		for deployed < needed {
 			fmt.Printf("\niteration start\n")
 			fmt.Printf("deployed = %d, needed = %d, service = %s\n", deployed, needed, service)
 
-			bestS, maxInstances := bestServer(retSolution, service)
+			bestS, maxInstances := tigo.bestServer(retSolution, service)
 			fmt.Printf("bestS = %s, maxInstances = %d\n", bestS, maxInstances)
 
-			if prevBestS == bestS {
-				break
+			if bestS == "" || maxInstances == 0 || prevBestS == bestS {
+				break // no edge node left that may run another replica
 			}
 			prevBestS = bestS
 			count := min(int64(needed-deployed), maxInstances) // TODO: nodeCapability()
@@ -148,24 +175,37 @@ func edgeReplacement(solution Solution) Solution {
 		}
 	}
 
+	p.repairIfEnabled(retSolution)
 	return retSolution
 }
 
-func tigo(BS int) Solution {
+// Optimize searches until no candidate improves any more and returns the
+// best solution and its score. Once ctx is done it stops after the current
+// round and returns the best solution seen so far.
+func (tigo *TIGO) Optimize(ctx context.Context) (Solution, float64, error) {
+	BS := tigo.BS
 	tempSls := []Solution{}
-	tempSls = append(tempSls, randomSolution())
+	tempSls = append(tempSls, tigo.Problem.randomSolution(tigo.Rand))
 	var SLs []Solution
 
 	for {
+		if ctx.Err() != nil {
+			fmt.Printf("Stopped: %v\n", ctx.Err())
+			SLs = append(SLs, tempSls...)
+			break
+		}
 		nextSls := []Solution{}
 		for _, sl := range tempSls {
-			cands := cloudExecSchemeImprove(sl, BS) // input solution and BS
+			cands, err := tigo.cloudExecSchemeImprove(ctx, sl, BS) // input solution and BS
+			if err != nil {
+				return nil, 0, err
+			}
 			if len(cands) == 0 {
 				SLs = append(SLs, sl)
 			} else {
 				for _, cand := range cands {
 					// fmt.Println("enter edgeReplacement()")
-					Xi1 := edgeReplacement(cand)
+					Xi1 := tigo.edgeReplacement(cand)
 					nextSls = append(nextSls, Xi1)
 				}
 			}
@@ -183,6 +223,11 @@ func tigo(BS int) Solution {
 			if len(nextSls) > BS {
 				nextSls = nextSls[:BS]
 			}
+			_, roundBest, err := tigo.best(nextSls)
+			if err != nil {
+				return nil, 0, err
+			}
+			tigo.History = append(tigo.History, roundBest)
 			tempSls = nextSls
 		}
 	}
@@ -193,18 +238,22 @@ func tigo(BS int) Solution {
 		common.PrintJSON(s, "")
 	}
 
-	return SLs[4]
+	return tigo.best(SLs)
 }
 
-func RunTIGO() {
-	start := time.Now()
-	InitTIGO()
-	BS := 5 // Branch Search Size
-	bestSolution := tigo(BS)
-	fmt.Println("Best Solution:")
-	common.PrintJSON(bestSolution, "")
-	elapsed := time.Since(start) // 計算花費時間
-	fmt.Printf("execution time(tigo): %s\n\n", elapsed)
-	// common.PrintJSON(bestSolution, "tigo_solution2.json")
-	// UpDateDeploymentsByJSON("tigo_solution2.json")
+// best returns the lowest-scoring solution of sls and its score.
+func (tigo *TIGO) best(sls []Solution) (Solution, float64, error) {
+	var bestSolution Solution
+	bestScore := math.Inf(1)
+	for _, sl := range sls {
+		score, err := tigo.Problem.Evaluate(sl)
+		if err != nil {
+			return nil, 0, err
+		}
+		if score < bestScore {
+			bestScore = score
+			bestSolution = sl
+		}
+	}
+	return bestSolution, bestScore, nil
 }
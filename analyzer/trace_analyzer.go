@@ -17,7 +17,6 @@ type StackNodeInfo struct {
 }
 
 var traceData common.TraceData
-var G = common.G
 
 func ExportCallCountsToCSV(callCount map[common.CallKey]int, filename string) error {
 	file, err := os.Create(filename)
@@ -95,8 +94,8 @@ func FindAllRoots(invCount InvocationCount) []string {
 }
 
 // / *** ExtICsFromCallGraph *** ///
-// Input: A trace: t; a call graph: G;
-func ExtICsFromCallGraph(NumI_t InvocationCount) (*InvocationChains, InvocationCount) {
+// Input: A trace: t; a call graph: G; the services of the application
+func ExtICsFromCallGraph(NumI_t InvocationCount, services []string) (*InvocationChains, InvocationCount) {
 	stack := utils.NewStack()
 	AddNode := utils.NewStack()
 	CurrentNum := utils.NewStack()
@@ -183,7 +182,7 @@ func ExtICsFromCallGraph(NumI_t InvocationCount) (*InvocationChains, InvocationC
 		}
 
 		// push childs of `n` to the `stack`
-		for _, s := range common.Services {
+		for _, s := range services {
 			if NumI_t.Exist(n, s) {
 				stack.Push(common.CallKey{From: n, To: s})
 			}
@@ -193,8 +192,10 @@ func ExtICsFromCallGraph(NumI_t InvocationCount) (*InvocationChains, InvocationC
 	return InvChains, newNumI_t
 }
 
-func getTotalInvChains() *InvocationChains {
-	common.LoadJSONFile("app.json", &traceData)
+func getTotalInvChains(filename string, services []string) (*InvocationChains, error) {
+	if err := common.LoadJSONFile(filename, &traceData); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", filename, err)
+	}
 
 	totalInvChains := NewInvocationChains()
 	for i, trace := range traceData.Data {
@@ -205,7 +206,7 @@ func getTotalInvChains() *InvocationChains {
 
 		InvChains := NewInvocationChains()
 		for len(NumI_t) > 0 {
-			tmpInvChains, newNumI_t := ExtICsFromCallGraph(NumI_t)
+			tmpInvChains, newNumI_t := ExtICsFromCallGraph(NumI_t, services)
 
 			// // Check if any invocation chains were extracted
 			// if len(tmpInvChains.Chains) > 0 {
@@ -242,10 +243,10 @@ func getTotalInvChains() *InvocationChains {
 	// 	fmt.Printf("  Chain: %s, Occurrences: %d\n", chainStr, count)
 	// }
 
-	return totalInvChains
+	return totalInvChains, nil
 }
 
-func DepIC(mi, mj string, InvChains InvocationChains) float64 {
+func DepIC(mi, mj string, InvChains InvocationChains, services []string) float64 {
 	Num_mi_mj := 0
 	Num_mi := 0
 	Num_mj := 0
@@ -270,15 +271,15 @@ func DepIC(mi, mj string, InvChains InvocationChains) float64 {
 	}
 
 	NumI_t := CountInvocationOfTraces(traceData)
-	for _, mx := range common.Services {
-		for _, my := range common.Services {
+	for _, mx := range services {
+		for _, my := range services {
 			invocationNum += NumI_t.GetCount(mx, my)
 		}
 	}
-	for _, mx := range common.Services {
+	for _, mx := range services {
 		invocationMi += NumI_t.GetCount(mi, mx)
 	}
-	for _, mx := range common.Services {
+	for _, mx := range services {
 		invocationMj += NumI_t.GetCount(mj, mx)
 	}
 	Cd_mi = float64(invocationMi) / float64(invocationNum)
@@ -374,46 +375,54 @@ func ExportDepICsToCSV(DepICs map[common.CallKey]float64, filename string) error
 	return nil
 }
 
-func GenerateAndSaveDepICs() {
-	totalInvChains := getTotalInvChains()
+// GenerateAndSaveDepICs computes DepIC for every service pair of the traces in
+// appFile and exports the result to output.
+func GenerateAndSaveDepICs(appFile, output string, topo *common.Topology) error {
+	totalInvChains, err := getTotalInvChains(appFile, topo.Services)
+	if err != nil {
+		return err
+	}
 	DepICs := make(map[common.CallKey]float64)
-	for _, mx := range common.Services {
-		for _, my := range common.Services {
+	for _, mx := range topo.Services {
+		for _, my := range topo.Services {
 			depIC := 0.0
 			if mx != my {
-				depIC = DepIC(mx, my, *totalInvChains)
+				depIC = DepIC(mx, my, *totalInvChains, topo.Services)
 			}
 			DepICs[common.CallKey{From: mx, To: my}] = depIC
 			// fmt.Printf("DepIC(%s, %s) = %f\n", mx, my, depIC)
 		}
 	}
 
-	err := ExportDepICsToCSV(DepICs, "depICs.csv")
-	if err != nil {
-		fmt.Printf("Error exporting DepICs to CSV: %v\n", err)
-	} else {
-		fmt.Println("DepICs data successfully exported to depICs.csv")
+	if err := ExportDepICsToCSV(DepICs, output); err != nil {
+		return fmt.Errorf("exporting DepICs to CSV: %w", err)
 	}
+	fmt.Printf("DepICs data successfully exported to %s\n", output)
+	return nil
 }
 
-func RunAnalyzer() {
-	totalInvChains := getTotalInvChains()
+// RunAnalyzer prints the invocation chains and DepIC matrix of the traces in
+// appFile and saves the DepICs to output.
+func RunAnalyzer(appFile, output string, topo *common.Topology) error {
+	totalInvChains, err := getTotalInvChains(appFile, topo.Services)
+	if err != nil {
+		return err
+	}
 	fmt.Println("----------------------------------------")
 	for chainStr, count := range totalInvChains.Chains {
 		fmt.Printf("  Chain: %s, Occurrences: %d\n", chainStr, count)
 	}
 
 	fmt.Println("-----------DepIC--------------------------")
-	for _, mx := range common.Services {
-		for _, my := range common.Services {
-			depIC := DepIC(mx, my, *totalInvChains)
+	for _, mx := range topo.Services {
+		for _, my := range topo.Services {
+			depIC := DepIC(mx, my, *totalInvChains, topo.Services)
 			fmt.Printf("DepIC(%s, %s) = %f\n", mx, my, depIC)
 		}
 	}
 
-	GenerateAndSaveDepICs()
-
 	// fmt.Println("-----------DepIC--------------------------")
 	// depIC := DepIC("checkoutservice", "emailservice", *totalInvChains)
 	// fmt.Printf("DepIC(%s, %s) = %f\n", "checkoutservice", "emailservice", depIC)
+	return GenerateAndSaveDepICs(appFile, output, topo)
 }
@@ -4,38 +4,62 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math/rand"
 	"os"
 	"sync"
 )
 
-var Nodes = []string{"vm1", "vm2", "vm3", "asus"}
-
 // Span represents a span within a trace, used in both Spans and spanMap.
 type Span struct {
-	TraceID       string `json:"traceID"`
-	SpanID        string `json:"spanID"`
-	OperationName string `json:"operationName"`
-	References    []struct {
-		RefType string `json:"refType"`
-		SpanID  string `json:"spanID"`
-	} `json:"references"`
-	StartTime       int64  `json:"startTime"`
-	Duration        int64  `json:"duration"`
-	ProcessID       string `json:"processID"`
-	ServiceName     string `json:"serviceName"`
-	ParentService   string `json:"parentService"`
-	ParentOperation string `json:"parentOperation"`
+	TraceID         string      `json:"traceID"`
+	SpanID          string      `json:"spanID"`
+	OperationName   string      `json:"operationName"`
+	References      []Reference `json:"references"`
+	StartTime       int64       `json:"startTime"`
+	Duration        int64       `json:"duration"`
+	ProcessID       string      `json:"processID"`
+	ServiceName     string      `json:"serviceName"`
+	ParentService   string      `json:"parentService"`
+	ParentOperation string      `json:"parentOperation"`
+	Tags            []KeyValue  `json:"tags,omitempty"`
+}
+
+// KeyValue is a span tag.
+type KeyValue struct {
+	Key   string `json:"key"`
+	Type  string `json:"type,omitempty"` // string, bool, int64, float64 or binary
+	Value any    `json:"value"`
+}
+
+// Tag returns the value of the tag key of s as text, and whether s has it.
+func (s Span) Tag(key string) (string, bool) {
+	for _, tag := range s.Tags {
+		if tag.Key == key {
+			return fmt.Sprint(tag.Value), true
+		}
+	}
+	return "", false
+}
+
+// Reference links a span to another, usually its parent.
+type Reference struct {
+	RefType string `json:"refType"` // RefChildOf for the parent
+	SpanID  string `json:"spanID"`
+}
+
+// RefChildOf is the RefType of a reference to the parent span.
+const RefChildOf = "CHILD_OF"
+
+// Process is the process that reported a span.
+type Process struct {
+	ServiceName string `json:"serviceName"`
 }
 
 type Trace struct {
-	TraceID           string `json:"traceID"`
-	Duration          int64  `json:"duration"`          // Microseconds (µs)
-	PredictedDuration int64  `json:"predictedDuration"` // Microseconds (µs)
-	Spans             []Span `json:"spans"`
-	Processes         map[string]struct {
-		ServiceName string `json:"serviceName"`
-	} `json:"processes"`
+	TraceID           string             `json:"traceID"`
+	Duration          int64              `json:"duration"`          // Microseconds (µs)
+	PredictedDuration int64              `json:"predictedDuration"` // Microseconds (µs)
+	Spans             []Span             `json:"spans"`
+	Processes         map[string]Process `json:"processes"`
 }
 
 // TraceData represents both the raw Jaeger API response and the target structure.
@@ -74,27 +98,19 @@ func PrintJSON(data interface{}, fileName string) {
 	}
 }
 
-var services = []string{
-	"cartservice", "checkoutservice", "currencyservice", "emailservice",
-	"frontend", "paymentservice", "productcatalogservice", "recommendationservice",
-	"redis-cart", "shippingservice",
-}
-
-var Services = []string{
-	"cartservice", "checkoutservice", "currencyservice", "emailservice",
-	"frontend", "paymentservice", "productcatalogservice", "recommendationservice",
-	"redis-cart", "shippingservice",
-}
-
 var traceData TraceData
 var processTimeMap map[string]map[string]int64
 var processTimeCloudMap map[string]map[string]int64
 
 var callCounts map[CallKey]int
 
+// Constraints are the resources a replica of a service requests, or the
+// capacity of a node.
 type Constraints struct {
-	CPU    int `json:"cpu"`
-	Memory int `json:"memory"`
+	CPU      int            `json:"cpu"`                // millicores
+	Memory   int            `json:"memory"`             // MiB
+	Pods     int            `json:"pods,omitempty"`     // nodes only: most replicas it runs; 0 means no limit
+	Extended map[string]int `json:"extended,omitempty"` // other resources, e.g. ephemeral-storage (MiB) or accelerators
 }
 
 type ResourceConstraints map[string]Constraints
@@ -116,6 +132,10 @@ type Particle struct {
 	Velocity     map[string]map[string]float64
 	BestSolution map[string]map[string]int
 	BestScore    float64
+	// Set on members of a Pareto front only
+	Objectives []float64 // latency, cost, colocation, replicas, spend, energy; all minimized
+	Feasible   bool
+	Source     string // algorithm that found the solution ("pso" or "gwo")
 }
 
 // SharedMemory holds Pareto fronts and synchronization data
@@ -128,8 +148,6 @@ type SharedMemory struct {
 	Transform   int // 0: no transform, 1: PSO to GWO, 2: GWO to PSO
 }
 
-var sharedMem SharedMemory
-
 func sumServiceInstances(filename string) {
 	// // Read the JSON file
 	// data, err := ioutil.ReadFile(filename)
@@ -179,68 +197,8 @@ func CalculateAverageDuration(traceData *TraceData) *TraceData {
 	return traceData
 }
 
-func randomSolutionForPS_GWCA() map[string]map[string]int {
-	solution := make(map[string]map[string]int)
-	for _, node := range Nodes {
-		solution[node] = make(map[string]int)
-		for _, service := range services {
-			solution[node][service] = 0
-		}
-	}
-
-	instances := make(map[string]int)
-	instances["cartservice"] = 6
-	instances["checkoutservice"] = 8
-	instances["currencyservice"] = 8
-	instances["emailservice"] = 4
-	instances["frontend"] = 7
-	instances["paymentservice"] = 5
-	instances["productcatalogservice"] = 6
-	instances["recommendationservice"] = 9
-	instances["redis-cart"] = 5
-	instances["shippingservice"] = 7
-
-	for _, service := range services {
-		// Generate random total instances for this service (1 to 10, adjust range as needed)
-		totalInstances := rand.Intn(10) + 1
-
-		// Randomly distribute the instances across nodes
-		for i := 0; i < totalInstances; i++ {
-			selectedNode := Nodes[rand.Intn(len(Nodes))]
-			solution[selectedNode][service]++
-		}
-	}
-
-	return solution
-}
-
 // / *** for analyzer *** ///
 type CallKey struct {
 	From string
 	To   string
 }
-
-var G = map[string]map[string]bool{
-	"frontend": {
-		"recommendationservice": true,
-		"productcatalogservice": true,
-		"cartservice":           true,
-		"shippingservice":       true,
-		"currencyservice":       true,
-		"checkoutservice":       true,
-	},
-	"recommendationservice": {
-		"productcatalogservice": true,
-	},
-	"checkoutservice": {
-		"productcatalogservice": true,
-		"cartservice":           true,
-		"shippingservice":       true,
-		"currencyservice":       true,
-		"paymentservice":        true,
-		"emailservice":          true,
-	},
-	"cartservice": {
-		"redis-cart": true,
-	},
-}
@@ -0,0 +1,64 @@
+package common
+
+import (
+	"fmt"
+)
+
+// Price is what one node charges for the resources replicas request.
+type Price struct {
+	CPU    float64 `json:"cpu"`    // per millicore-hour
+	Memory float64 `json:"memory"` // per MiB-hour
+}
+
+// Egress is the price of the traffic from nodes of one tier to another.
+type Egress struct {
+	From  string  `json:"from"` // tier
+	To    string  `json:"to"`   // tier
+	PerGB float64 `json:"perGB"`
+}
+
+// CostProfile prices the resources of the nodes and the traffic between
+// tiers, as read from a cost profile file. Prices share one currency.
+type CostProfile struct {
+	Nodes  map[string]Price `json:"nodes"` // node name or class -> price
+	Egress []Egress         `json:"egress"`
+}
+
+// LoadCostProfile reads a cost profile file.
+func LoadCostProfile(filename string) (*CostProfile, error) {
+	var profile CostProfile
+	if err := LoadJSONFile(filename, &profile); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", filename, err)
+	}
+	for name, price := range profile.Nodes {
+		if price.CPU < 0 || price.Memory < 0 {
+			return nil, fmt.Errorf("%s: node %s has a negative price", filename, name)
+		}
+	}
+	for _, egress := range profile.Egress {
+		if egress.PerGB < 0 {
+			return nil, fmt.Errorf("%s: egress %s -> %s has a negative price", filename, egress.From, egress.To)
+		}
+	}
+	return &profile, nil
+}
+
+// PriceOf returns the price of node: its own entry, else the entry of its
+// class or tier. A node without an entry is free.
+func (cp *CostProfile) PriceOf(node Node) Price {
+	if price, ok := cp.Nodes[node.Name]; ok {
+		return price
+	}
+	return cp.Nodes[node.ClassName()]
+}
+
+// EgressPerGB returns the price of a GB sent from a node of tier from to a
+// node of tier to; traffic between tiers without an entry is free.
+func (cp *CostProfile) EgressPerGB(from, to string) float64 {
+	for _, egress := range cp.Egress {
+		if egress.From == from && egress.To == to {
+			return egress.PerGB
+		}
+	}
+	return 0
+}
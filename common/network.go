@@ -0,0 +1,110 @@
+package common
+
+import "fmt"
+
+// ConnectionStats holds latency and bandwidth between two nodes
+type ConnectionStats struct {
+	Latency   int // in milliseconds
+	Bandwidth int // in Mbps
+}
+
+type LatencyBandwidthMap struct {
+	Stats map[string]map[string]ConnectionStats // Map of from-node to to-node stats
+}
+
+// Constructor to initialize the map
+func NewLatencyBandwidthMap() *LatencyBandwidthMap {
+	return &LatencyBandwidthMap{
+		Stats: make(map[string]map[string]ConnectionStats),
+	}
+}
+
+// Method to set latency and bandwidth between two nodes
+func (lbm *LatencyBandwidthMap) SetStats(from, to string, latency, bandwidth int) {
+	if _, exists := lbm.Stats[from]; !exists {
+		lbm.Stats[from] = make(map[string]ConnectionStats)
+	}
+	lbm.Stats[from][to] = ConnectionStats{Latency: latency, Bandwidth: bandwidth}
+}
+
+// Method to get latency and bandwidth between two nodes
+func (lbm *LatencyBandwidthMap) GetStats(from, to string) (ConnectionStats, bool) {
+	if toMap, exists := lbm.Stats[from]; exists {
+		if stats, ok := toMap[to]; ok {
+			return stats, true
+		}
+	}
+	return ConnectionStats{}, false // Return empty stats and false if not found
+}
+
+// Link is one entry of a network profile.
+type Link struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Latency   int    `json:"latency"`   // ms
+	Bandwidth int    `json:"bandwidth"` // Mbps
+}
+
+// CallPayload is the size of the data sent by one call from a service to
+// another.
+type CallPayload struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Bytes int    `json:"bytes"`
+}
+
+// NetworkProfile describes the links between nodes and the payload of calls
+// between services, as read from a network profile file.
+type NetworkProfile struct {
+	Links          []Link        `json:"links"`
+	Symmetric      bool          `json:"symmetric"` // every link also applies from To to From
+	Payloads       []CallPayload `json:"payloads"`
+	DefaultPayload int           `json:"defaultPayload"` // bytes of a call not listed in Payloads
+}
+
+// LoadNetworkProfile reads a network profile file.
+func LoadNetworkProfile(filename string) (*NetworkProfile, error) {
+	var profile NetworkProfile
+	if err := LoadJSONFile(filename, &profile); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", filename, err)
+	}
+	for _, link := range profile.Links {
+		if link.Latency < 0 || link.Bandwidth < 0 {
+			return nil, fmt.Errorf("%s: link %s -> %s has a negative latency or bandwidth", filename, link.From, link.To)
+		}
+	}
+	return &profile, nil
+}
+
+// LatencyBandwidthMap returns the links of the profile as a map.
+func (np *NetworkProfile) LatencyBandwidthMap() *LatencyBandwidthMap {
+	lbm := NewLatencyBandwidthMap()
+	for _, link := range np.Links {
+		lbm.SetStats(link.From, link.To, link.Latency, link.Bandwidth)
+		if np.Symmetric {
+			if _, exists := lbm.GetStats(link.To, link.From); !exists {
+				lbm.SetStats(link.To, link.From, link.Latency, link.Bandwidth)
+			}
+		}
+	}
+	return lbm
+}
+
+// PayloadBytes returns the payload of every call listed in the profile.
+func (np *NetworkProfile) PayloadBytes() map[CallKey]int {
+	payloads := make(map[CallKey]int, len(np.Payloads))
+	for _, payload := range np.Payloads {
+		payloads[CallKey{From: payload.From, To: payload.To}] = payload.Bytes
+	}
+	return payloads
+}
+
+// TransferTime returns the time in ms to send bytes over a link: its latency
+// plus the time the payload takes at its bandwidth.
+func (cs ConnectionStats) TransferTime(bytes int) float64 {
+	t := float64(cs.Latency)
+	if cs.Bandwidth > 0 {
+		t += float64(bytes) * 8 / (float64(cs.Bandwidth) * 1000) // bits / (bits per ms)
+	}
+	return t
+}
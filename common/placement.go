@@ -0,0 +1,123 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Placement rule types.
+const (
+	RulePin      = "pin"      // Service runs only on Nodes
+	RuleForbid   = "forbid"   // Service never runs on Nodes
+	RuleColocate = "colocate" // every node running Service also runs With
+	RuleSpread   = "spread"   // Service runs on at least Min nodes
+	RuleReplicas = "replicas" // Service has at least Min and, if set, at most Max replicas
+	RuleLocality = "locality" // Service runs on a single node, one of Nodes if any
+)
+
+// PlacementRule is one declarative constraint on where a service's replicas
+// may run.
+type PlacementRule struct {
+	Type    string   `json:"type"`
+	Service string   `json:"service"`
+	Nodes   []string `json:"nodes,omitempty"` // node names or classes
+	With    string   `json:"with,omitempty"`  // colocate: the service to run next to
+	Min     int      `json:"min,omitempty"`
+	Max     int      `json:"max,omitempty"` // replicas: 0 means no upper bound
+}
+
+// PlacementRules are the placement constraints of a deployment, as read from
+// a placement file.
+type PlacementRules struct {
+	Rules []PlacementRule `json:"rules"`
+}
+
+// LoadPlacementRules reads a placement file and checks its rules against
+// topology.
+func LoadPlacementRules(filename string, topology *Topology) (*PlacementRules, error) {
+	var rules PlacementRules
+	if err := LoadJSONFile(filename, &rules); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", filename, err)
+	}
+	if err := rules.Validate(topology); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return &rules, nil
+}
+
+// Validate checks that every rule has a known type, refers to declared
+// services and nodes, and has the fields its type needs.
+func (pr *PlacementRules) Validate(topology *Topology) error {
+	for i, rule := range pr.Rules {
+		if !topology.HasService(rule.Service) {
+			return fmt.Errorf("rule %d (%s): unknown service %q", i, rule.Type, rule.Service)
+		}
+		for _, name := range rule.Nodes {
+			known := false
+			for _, node := range topology.Nodes {
+				known = known || node.Name == name || node.ClassName() == name
+			}
+			if !known {
+				return fmt.Errorf("rule %d (%s): no node or class %q", i, rule.Type, name)
+			}
+		}
+		switch rule.Type {
+		case RulePin, RuleForbid:
+			if len(rule.Nodes) == 0 {
+				return fmt.Errorf("rule %d (%s): no nodes", i, rule.Type)
+			}
+		case RuleColocate:
+			if !topology.HasService(rule.With) {
+				return fmt.Errorf("rule %d (%s): unknown service %q", i, rule.Type, rule.With)
+			}
+		case RuleSpread:
+			if rule.Min < 1 {
+				return fmt.Errorf("rule %d (%s): min must be at least 1", i, rule.Type)
+			}
+		case RuleReplicas:
+			if rule.Min < 0 || rule.Max < 0 || (rule.Max > 0 && rule.Max < rule.Min) {
+				return fmt.Errorf("rule %d (%s): bad bounds [%d, %d]", i, rule.Type, rule.Min, rule.Max)
+			}
+		case RuleLocality:
+		default:
+			return fmt.Errorf("rule %d: unknown type %q", i, rule.Type)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether node is one of the rule's nodes, by name or class.
+func (r PlacementRule) Matches(node Node) bool {
+	for _, name := range r.Nodes {
+		if name == node.Name || name == node.ClassName() {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the rule, e.g. "pin redis-cart to [vm1]".
+func (r PlacementRule) String() string {
+	nodes := "[" + strings.Join(r.Nodes, " ") + "]"
+	switch r.Type {
+	case RulePin:
+		return fmt.Sprintf("pin %s to %s", r.Service, nodes)
+	case RuleForbid:
+		return fmt.Sprintf("forbid %s on %s", r.Service, nodes)
+	case RuleColocate:
+		return fmt.Sprintf("colocate %s with %s", r.Service, r.With)
+	case RuleSpread:
+		return fmt.Sprintf("spread %s over at least %d nodes", r.Service, r.Min)
+	case RuleReplicas:
+		if r.Max > 0 {
+			return fmt.Sprintf("%d to %d replicas of %s", r.Min, r.Max, r.Service)
+		}
+		return fmt.Sprintf("at least %d replicas of %s", r.Min, r.Service)
+	case RuleLocality:
+		if len(r.Nodes) > 0 {
+			return fmt.Sprintf("keep %s on one node of %s", r.Service, nodes)
+		}
+		return fmt.Sprintf("keep %s on one node", r.Service)
+	}
+	return r.Type + " " + r.Service
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"fmt"
+)
+
+// Power is the draw of one node: a fixed idle draw plus a draw per core its
+// replicas request.
+type Power struct {
+	Idle    float64 `json:"idle"`    // W
+	PerCore float64 `json:"perCore"` // W per requested core
+}
+
+// PowerProfile holds the power model of the nodes, as read from a power
+// profile file.
+type PowerProfile struct {
+	Nodes map[string]Power `json:"nodes"` // node name or class -> power
+}
+
+// LoadPowerProfile reads a power profile file.
+func LoadPowerProfile(filename string) (*PowerProfile, error) {
+	var profile PowerProfile
+	if err := LoadJSONFile(filename, &profile); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", filename, err)
+	}
+	for name, power := range profile.Nodes {
+		if power.Idle < 0 || power.PerCore < 0 {
+			return nil, fmt.Errorf("%s: node %s has a negative power draw", filename, name)
+		}
+	}
+	return &profile, nil
+}
+
+// PowerOf returns the power model of node: its own entry, else the entry of
+// its class or tier, and whether there is one.
+func (pp *PowerProfile) PowerOf(node Node) (Power, bool) {
+	if power, ok := pp.Nodes[node.Name]; ok {
+		return power, true
+	}
+	power, ok := pp.Nodes[node.ClassName()]
+	return power, ok
+}
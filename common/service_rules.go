@@ -0,0 +1,92 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ServiceRule attributes the spans it matches to a virtual service, such as
+// a database or cache instrumented only from the client side. A span matches
+// if its operation matches Operation and, if Tag is set, it carries Tag with
+// a value matching Value; an empty pattern matches anything.
+type ServiceRule struct {
+	Operation string `json:"operation,omitempty"` // regexp on the operation name
+	Tag       string `json:"tag,omitempty"`       // span tag, e.g. db.system or peer.service
+	Value     string `json:"value,omitempty"`     // regexp on the tag value
+	Service   string `json:"service,omitempty"`   // empty with a Tag: the tag value
+
+	operation, value *regexp.Regexp
+}
+
+// ServiceRules holds the rules attributing spans to services, as read from
+// a service rules file.
+type ServiceRules struct {
+	Rules []ServiceRule `json:"rules"`
+}
+
+// NewServiceRules checks rules and returns them ready for use.
+func NewServiceRules(rules ...ServiceRule) (*ServiceRules, error) {
+	sr := &ServiceRules{Rules: rules}
+	if err := sr.compile(); err != nil {
+		return nil, err
+	}
+	return sr, nil
+}
+
+// LoadServiceRules reads a service rules file.
+func LoadServiceRules(filename string) (*ServiceRules, error) {
+	var rules ServiceRules
+	if err := LoadJSONFile(filename, &rules); err != nil {
+		return nil, fmt.Errorf("loading %s: %w", filename, err)
+	}
+	if err := rules.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return &rules, nil
+}
+
+// compile checks the rules and compiles their patterns.
+func (sr *ServiceRules) compile() error {
+	for i := range sr.Rules {
+		rule := &sr.Rules[i]
+		if rule.Operation == "" && rule.Tag == "" {
+			return fmt.Errorf("rule %d: needs an operation or a tag", i+1)
+		}
+		if rule.Service == "" && rule.Tag == "" {
+			return fmt.Errorf("rule %d: needs a service", i+1)
+		}
+		var err error
+		if rule.operation, err = regexp.Compile(rule.Operation); err != nil {
+			return fmt.Errorf("rule %d: operation: %w", i+1, err)
+		}
+		if rule.value, err = regexp.Compile(rule.Value); err != nil {
+			return fmt.Errorf("rule %d: value: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ServiceOf returns the service the first matching rule attributes span to,
+// and false if no rule matches. A nil *ServiceRules matches nothing.
+func (sr *ServiceRules) ServiceOf(span Span) (string, bool) {
+	if sr == nil {
+		return "", false
+	}
+	for _, rule := range sr.Rules {
+		if !rule.operation.MatchString(span.OperationName) {
+			continue
+		}
+		if rule.Tag == "" {
+			return rule.Service, true
+		}
+		value, ok := span.Tag(rule.Tag)
+		if !ok || !rule.value.MatchString(value) {
+			continue
+		}
+		if rule.Service == "" {
+			return value, true
+		}
+		return rule.Service, true
+	}
+	return "", false
+}
@@ -0,0 +1,165 @@
+package common
+
+import (
+	"fmt"
+)
+
+// Node tiers understood by the optimizers.
+const (
+	TierEdge  = "edge"
+	TierCloud = "cloud"
+)
+
+// Node is a cluster node that replicas can be placed on.
+type Node struct {
+	Name  string  `json:"name"`
+	Tier  string  `json:"tier"`            // TierEdge or TierCloud
+	Class string  `json:"class,omitempty"` // hardware class sharing a processing-time table; defaults to Tier
+	Speed float64 `json:"speed,omitempty"` // speed relative to the table of its class or tier; defaults to 1
+}
+
+// ClassName returns the node's class, or its tier if it has none.
+func (n Node) ClassName() string {
+	if n.Class != "" {
+		return n.Class
+	}
+	return n.Tier
+}
+
+// IsCloud reports whether the node is in the cloud tier.
+func (n Node) IsCloud() bool {
+	return n.Tier == TierCloud
+}
+
+// SpeedFactor returns the node's speed, or 1 if it has none.
+func (n Node) SpeedFactor() float64 {
+	if n.Speed > 0 {
+		return n.Speed
+	}
+	return 1
+}
+
+// Topology describes the application and the cluster it is deployed on.
+type Topology struct {
+	Namespace  string              `json:"namespace"`  // Kubernetes namespace of the application
+	Entrypoint string              `json:"entrypoint"` // service receiving user requests, kept off the cloud
+	Nodes      []Node              `json:"nodes"`
+	Services   []string            `json:"services"`
+	CallGraph  map[string][]string `json:"callGraph"` // caller -> callees
+}
+
+// LoadTopology reads and validates a topology file.
+func LoadTopology(filename string) (*Topology, error) {
+	var topology Topology
+	if err := LoadJSONFile(filename, &topology); err != nil {
+		return nil, fmt.Errorf("loading topology %s: %w", filename, err)
+	}
+	if err := topology.Validate(); err != nil {
+		return nil, fmt.Errorf("topology %s: %w", filename, err)
+	}
+	return &topology, nil
+}
+
+// Validate checks that nodes have a known tier and that the entrypoint and the
+// call graph only refer to declared services.
+func (t *Topology) Validate() error {
+	if len(t.Nodes) == 0 {
+		return fmt.Errorf("no nodes")
+	}
+	if len(t.Services) == 0 {
+		return fmt.Errorf("no services")
+	}
+
+	seen := make(map[string]bool)
+	for _, node := range t.Nodes {
+		if node.Name == "" {
+			return fmt.Errorf("node without a name")
+		}
+		if node.Tier != TierEdge && node.Tier != TierCloud {
+			return fmt.Errorf("node %s: unknown tier %q", node.Name, node.Tier)
+		}
+		if seen[node.Name] {
+			return fmt.Errorf("node %s declared twice", node.Name)
+		}
+		if node.Speed < 0 {
+			return fmt.Errorf("node %s: negative speed %v", node.Name, node.Speed)
+		}
+		seen[node.Name] = true
+	}
+
+	if t.Entrypoint != "" && !t.HasService(t.Entrypoint) {
+		return fmt.Errorf("entrypoint %s is not a service", t.Entrypoint)
+	}
+	for from, callees := range t.CallGraph {
+		if !t.HasService(from) {
+			return fmt.Errorf("call graph: unknown service %s", from)
+		}
+		for _, to := range callees {
+			if !t.HasService(to) {
+				return fmt.Errorf("call graph: unknown service %s", to)
+			}
+		}
+	}
+	return nil
+}
+
+// NodeNames returns the names of all nodes in declaration order.
+func (t *Topology) NodeNames() []string {
+	names := make([]string, len(t.Nodes))
+	for i, node := range t.Nodes {
+		names[i] = node.Name
+	}
+	return names
+}
+
+// EdgeNodes returns the names of the edge-tier nodes.
+func (t *Topology) EdgeNodes() []string {
+	return t.tierNodes(TierEdge)
+}
+
+// CloudNodes returns the names of the cloud-tier nodes.
+func (t *Topology) CloudNodes() []string {
+	return t.tierNodes(TierCloud)
+}
+
+func (t *Topology) tierNodes(tier string) []string {
+	var names []string
+	for _, node := range t.Nodes {
+		if node.Tier == tier {
+			names = append(names, node.Name)
+		}
+	}
+	return names
+}
+
+// IsCloud reports whether node belongs to the cloud tier.
+func (t *Topology) IsCloud(node string) bool {
+	for _, n := range t.Nodes {
+		if n.Name == node {
+			return n.IsCloud()
+		}
+	}
+	return false
+}
+
+// HasService reports whether service is part of the application.
+func (t *Topology) HasService(service string) bool {
+	for _, s := range t.Services {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
+// Graph returns the call graph as an adjacency set.
+func (t *Topology) Graph() map[string]map[string]bool {
+	g := make(map[string]map[string]bool, len(t.CallGraph))
+	for from, callees := range t.CallGraph {
+		g[from] = make(map[string]bool, len(callees))
+		for _, to := range callees {
+			g[from][to] = true
+		}
+	}
+	return g
+}
@@ -2,7 +2,7 @@ package main
 
 import (
 	"fmt"
-	"log"
+	"optimizer/common"
 	"strings"
 	// "encoding/json" // Assuming loadJSONFile and printJSON are in common.go or another accessible file
 	// "os" // Assuming loadJSONFile and printJSON use os
@@ -30,16 +30,8 @@ func init() {
 
 // analyzeSingleTrace is a helper to process one trace instance from TraceData.Data
 // This function will do the heavy lifting for each individual trace.
-func analyzeSingleTrace(traceInstance struct {
-	TraceID           string `json:"traceID"`
-	Duration          int64  `json:"duration"`
-	PredictedDuration int64  `json:"predictedDuration"`
-	Spans             []Span `json:"spans"` // Assuming Span struct is defined in common.go
-	Processes         map[string]struct {
-		ServiceName string `json:"serviceName"`
-	} `json:"processes"`
-}) {
-	spanMap := make(map[string]Span)
+func analyzeSingleTrace(traceInstance common.Trace) {
+	spanMap := make(map[string]common.Span)
 	for _, span := range traceInstance.Spans {
 		spanMap[span.SpanID] = span
 	}
@@ -74,7 +66,7 @@ func analyzeSingleTrace(traceInstance struct {
 }
 
 // extractInvocationChain is now the main entry point to process ALL traces in TraceData
-func extractInvocationChain(allTraceData TraceData) { // Assuming TraceData struct is defined in common.go
+func extractInvocationChain(allTraceData common.TraceData) { // TraceData is defined in the common package
 	// Iterate through each individual trace instance within the Data slice
 	for _, traceInstance := range allTraceData.Data {
 		analyzeSingleTrace(traceInstance) // Process each trace one by one
@@ -84,7 +76,7 @@ func extractInvocationChain(allTraceData TraceData) { // Assuming TraceData stru
 
 // Helper to find root spans in a trace
 // Assumes Span struct is accessible
-func findRootSpans(spans []Span, spanMap map[string]Span) []Span {
+func findRootSpans(spans []common.Span, spanMap map[string]common.Span) []common.Span {
 	isChild := make(map[string]bool)
 	for _, span := range spans {
 		for _, ref := range span.References {
@@ -96,7 +88,7 @@ func findRootSpans(spans []Span, spanMap map[string]Span) []Span {
 		}
 	}
 
-	var roots []Span
+	var roots []common.Span
 	for _, span := range spans {
 		if !isChild[span.SpanID] {
 			roots = append(roots, span)
@@ -107,10 +99,10 @@ func findRootSpans(spans []Span, spanMap map[string]Span) []Span {
 
 // buildChainDFS (simplified for a single primary chain)
 // Accepts *InvocationChainType to match InvocationChainType as []string
-func buildChainDFS(current Span, spanMap map[string]Span, chain *InvocationChainType) { // <--- 修正這裡的型別
+func buildChainDFS(current common.Span, spanMap map[string]common.Span, chain *InvocationChainType) { // <--- 修正這裡的型別
 	*chain = append(*chain, current.ServiceName)
 
-	var children []Span
+	var children []common.Span
 	for _, s := range spanMap {
 		for _, ref := range s.References {
 			if ref.RefType == "CHILD_OF" && ref.SpanID == current.SpanID {
@@ -236,15 +228,13 @@ func DepIC(mj, mk string) float64 {
 
 // *** for (6) and (7) *** //
 
-// RunDependency is the main entry point for the dependency analysis
-// Assumes loadJSONFile and traceData are accessible (e.g., from common.go/main.go setup)
-func RunDependency() {
-	// Load your Jaeger trace JSON data
-	// Assuming traceData is a global variable of type TraceData defined elsewhere (e.g., common.go or main.go)
-	// And loadJSONFile is a function defined elsewhere (e.g., common.go)
-	err := loadJSONFile("app.json", &traceData)
-	if err != nil {
-		log.Fatalf("Error loading trace data: %v", err)
+// RunDependency is the main entry point for the dependency analysis. It
+// prints the DepIC of every pair in pairs, or of every caller and callee of
+// the topology's call graph if pairs is empty.
+func RunDependency(filename string, topo *common.Topology, pairs [][2]string) error {
+	var traceData common.TraceData
+	if err := loadJSONFile(filename, &traceData); err != nil {
+		return fmt.Errorf("loading trace data: %w", err)
 	}
 
 	// Now, call the main analysis function with the loaded TraceData
@@ -260,6 +250,16 @@ func RunDependency() {
 		fmt.Printf("Call: %s -> %s, Count: %d\n", call.Caller, call.Callee, count)
 	}
 
-	depIC_sp := DepIC("frontend", "checkoutservice")
-	fmt.Printf("DepIC(\"frontend\", \"checkoutservice\"): %.4f\n", depIC_sp)
+	if len(pairs) == 0 {
+		for _, caller := range topo.Services {
+			for _, callee := range topo.CallGraph[caller] {
+				pairs = append(pairs, [2]string{caller, callee})
+			}
+		}
+	}
+	fmt.Println("\n--- DepIC ---")
+	for _, pair := range pairs {
+		fmt.Printf("DepIC(%q, %q): %.4f\n", pair[0], pair[1], DepIC(pair[0], pair[1]))
+	}
+	return nil
 }
@@ -0,0 +1,122 @@
+// Package importer converts trace exports of other tracing systems into the
+// trace model of the common package, so the analyzer and the optimizers can
+// work on them as on traces fetched from Jaeger.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"optimizer/common"
+	"os"
+)
+
+// Formats of trace exports.
+const (
+	FormatOTLP   = "otlp"   // OTLP/JSON, as written by the OpenTelemetry collector
+	FormatZipkin = "zipkin" // Zipkin v2 JSON
+)
+
+// Formats returns the formats Load understands.
+func Formats() []string {
+	return []string{FormatOTLP, FormatZipkin}
+}
+
+// unknownService is the service of spans that do not name one, after the
+// service.name OpenTelemetry SDKs report when none is configured.
+const unknownService = "unknown_service"
+
+// readers maps every format to the function reading it.
+var readers = map[string]func(io.Reader) (*common.TraceData, error){
+	FormatOTLP:   ReadOTLP,
+	FormatZipkin: ReadZipkin,
+}
+
+// Load reads the export in format from filename.
+func Load(format, filename string) (*common.TraceData, error) {
+	read, ok := readers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown trace format %q (want one of %v)", format, Formats())
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	traces, err := read(file)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", filename, err)
+	}
+	return traces, nil
+}
+
+// builder groups spans into traces in the order their traces first appear.
+type builder struct {
+	order  []string
+	traces map[string]*common.Trace
+}
+
+func newBuilder() *builder {
+	return &builder{traces: make(map[string]*common.Trace)}
+}
+
+// add adds span, reported by service, to its trace. A parent of "" makes it
+// a root span.
+func (b *builder) add(span common.Span, service, parent string) {
+	trace, ok := b.traces[span.TraceID]
+	if !ok {
+		trace = &common.Trace{TraceID: span.TraceID, Processes: make(map[string]common.Process)}
+		b.traces[span.TraceID] = trace
+		b.order = append(b.order, span.TraceID)
+	}
+
+	// one process per service, named like Jaeger names them
+	for id, process := range trace.Processes {
+		if process.ServiceName == service {
+			span.ProcessID = id
+		}
+	}
+	if span.ProcessID == "" {
+		span.ProcessID = fmt.Sprintf("p%d", len(trace.Processes)+1)
+		trace.Processes[span.ProcessID] = common.Process{ServiceName: service}
+	}
+	span.ServiceName = service
+	if parent != "" {
+		span.References = []common.Reference{{RefType: common.RefChildOf, SpanID: parent}}
+	}
+	trace.Spans = append(trace.Spans, span)
+}
+
+// traceData returns the traces built, with the parent of every span and the
+// duration of every trace filled in as the Jaeger preprocessing does: a span
+// whose parent is not in the trace gets "none", and a trace lasts from its
+// first span start to its last span end.
+func (b *builder) traceData() (*common.TraceData, error) {
+	if len(b.order) == 0 {
+		return nil, fmt.Errorf("no spans")
+	}
+	var data common.TraceData
+	for _, id := range b.order {
+		trace := b.traces[id]
+		byID := make(map[string]common.Span, len(trace.Spans))
+		for _, span := range trace.Spans {
+			byID[span.SpanID] = span
+		}
+
+		start, end := trace.Spans[0].StartTime, int64(0)
+		for i := range trace.Spans {
+			span := &trace.Spans[i]
+			span.ParentService, span.ParentOperation = "none", "none"
+			for _, ref := range span.References {
+				if parent, ok := byID[ref.SpanID]; ok && ref.RefType == common.RefChildOf {
+					span.ParentService, span.ParentOperation = parent.ServiceName, parent.OperationName
+				}
+			}
+			start = min(start, span.StartTime)
+			end = max(end, span.StartTime+span.Duration)
+		}
+		trace.Duration = end - start
+		data.Data = append(data.Data, *trace)
+	}
+	return common.CalculateAverageDuration(&data), nil
+}
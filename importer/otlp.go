@@ -0,0 +1,139 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"optimizer/common"
+	"strconv"
+)
+
+// otlpTraces is an OTLP/JSON ExportTraceServiceRequest.
+type otlpTraces struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []otlpAttribute `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+		// the name of scopeSpans before OTLP 0.15
+		InstrumentationLibrarySpans []otlpScopeSpans `json:"instrumentationLibrarySpans"`
+	} `json:"resourceSpans"`
+}
+
+type otlpScopeSpans struct {
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId"`
+	Name              string          `json:"name"`
+	StartTimeUnixNano unixNano        `json:"startTimeUnixNano"`
+	EndTimeUnixNano   unixNano        `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue *string  `json:"stringValue"`
+		BoolValue   *bool    `json:"boolValue"`
+		IntValue    *string  `json:"intValue"` // int64 as a string
+		DoubleValue *float64 `json:"doubleValue"`
+	} `json:"value"`
+}
+
+// tag returns a as a span tag, and false for values other than scalars.
+func (a otlpAttribute) tag() (common.KeyValue, bool) {
+	v := a.Value
+	switch {
+	case v.StringValue != nil:
+		return common.KeyValue{Key: a.Key, Type: "string", Value: *v.StringValue}, true
+	case v.BoolValue != nil:
+		return common.KeyValue{Key: a.Key, Type: "bool", Value: *v.BoolValue}, true
+	case v.IntValue != nil:
+		n, err := strconv.ParseInt(*v.IntValue, 10, 64)
+		return common.KeyValue{Key: a.Key, Type: "int64", Value: n}, err == nil
+	case v.DoubleValue != nil:
+		return common.KeyValue{Key: a.Key, Type: "float64", Value: *v.DoubleValue}, true
+	}
+	return common.KeyValue{}, false
+}
+
+// unixNano is a time in ns since the epoch. OTLP/JSON writes 64-bit
+// integers as strings, but some exporters write plain numbers.
+type unixNano int64
+
+func (n *unixNano) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		s = string(b)
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("time %s: %w", b, err)
+	}
+	*n = unixNano(v)
+	return nil
+}
+
+// ReadOTLP reads OTLP/JSON trace exports from r: one export, or one per line
+// as the file exporter of the collector writes them. The service of a span is
+// the service.name attribute of its resource.
+func ReadOTLP(r io.Reader) (*common.TraceData, error) {
+	b := newBuilder()
+	dec := json.NewDecoder(r)
+	for {
+		var export otlpTraces
+		if err := dec.Decode(&export); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, rs := range export.ResourceSpans {
+			service := attribute(rs.Resource.Attributes, "service.name")
+			if service == "" {
+				service = unknownService
+			}
+			for _, ss := range append(rs.ScopeSpans, rs.InstrumentationLibrarySpans...) {
+				for _, s := range ss.Spans {
+					if s.TraceID == "" || s.SpanID == "" {
+						return nil, fmt.Errorf("span %q of %s without a trace or span id", s.Name, service)
+					}
+					b.add(common.Span{
+						TraceID:       s.TraceID,
+						SpanID:        s.SpanID,
+						OperationName: s.Name,
+						StartTime:     int64(s.StartTimeUnixNano) / 1000,
+						Duration:      int64(s.EndTimeUnixNano-s.StartTimeUnixNano) / 1000,
+						Tags:          tags(s.Attributes),
+					}, service, s.ParentSpanID)
+				}
+			}
+		}
+	}
+	return b.traceData()
+}
+
+// attribute returns the string value of key in attributes, or "".
+func attribute(attributes []otlpAttribute, key string) string {
+	for _, a := range attributes {
+		if a.Key == key && a.Value.StringValue != nil {
+			return *a.Value.StringValue
+		}
+	}
+	return ""
+}
+
+// tags returns the scalar attributes as span tags.
+func tags(attributes []otlpAttribute) []common.KeyValue {
+	var tags []common.KeyValue
+	for _, a := range attributes {
+		if tag, ok := a.tag(); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,83 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+// Two exports on separate lines, as the collector file exporter writes them:
+// frontend calls cart, and cart's span arrives in a later export.
+const otlpExport = `{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"frontend"}}]},
+ "scopeSpans":[{"spans":[
+  {"traceId":"t1","spanId":"a","name":"GET /cart","startTimeUnixNano":"1000000","endTimeUnixNano":"9000000"},
+  {"traceId":"t1","spanId":"b","parentSpanId":"a","name":"GetCart","startTimeUnixNano":"2000000","endTimeUnixNano":"6000000"},
+  {"traceId":"t2","spanId":"x","parentSpanId":"gone","name":"GET /","startTimeUnixNano":5000000,"endTimeUnixNano":7000000}]}]}]}
+{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"cart"}}]},
+ "instrumentationLibrarySpans":[{"spans":[
+  {"traceId":"t1","spanId":"c","parentSpanId":"b","name":"/Cart/GetCart","startTimeUnixNano":"3000000","endTimeUnixNano":"10000000",
+   "attributes":[{"key":"db.system","value":{"stringValue":"redis"}},{"key":"net.peer.port","value":{"intValue":"6379"}},{"key":"list","value":{"arrayValue":{}}}]}]}]}]}
+`
+
+func TestReadOTLP(t *testing.T) {
+	traces, err := ReadOTLP(strings.NewReader(otlpExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces.Data) != 2 {
+		t.Fatalf("%d traces, want 2", len(traces.Data))
+	}
+
+	t1 := traces.Data[0]
+	if t1.TraceID != "t1" || len(t1.Spans) != 3 || len(t1.Processes) != 2 {
+		t.Fatalf("trace t1 = %+v", t1)
+	}
+	if t1.Duration != 9000 { // 1 ms to 10 ms, in µs
+		t.Errorf("t1 lasts %d µs, want 9000", t1.Duration)
+	}
+	want := []struct{ service, parentService, parentOperation string }{
+		{"frontend", "none", "none"},
+		{"frontend", "frontend", "GET /cart"},
+		{"cart", "frontend", "GetCart"},
+	}
+	for i, w := range want {
+		span := t1.Spans[i]
+		if span.ServiceName != w.service || span.ParentService != w.parentService || span.ParentOperation != w.parentOperation {
+			t.Errorf("span %s: %s called by %s/%s, want %s called by %s/%s", span.SpanID,
+				span.ServiceName, span.ParentService, span.ParentOperation, w.service, w.parentService, w.parentOperation)
+		}
+		if t1.Processes[span.ProcessID].ServiceName != w.service {
+			t.Errorf("span %s: process %q is not %s", span.SpanID, span.ProcessID, w.service)
+		}
+	}
+	if v, ok := t1.Spans[2].Tag("db.system"); !ok || v != "redis" {
+		t.Errorf("db.system = %q, %v", v, ok)
+	}
+	if v, _ := t1.Spans[2].Tag("net.peer.port"); v != "6379" || len(t1.Spans[2].Tags) != 2 {
+		t.Errorf("tags = %v", t1.Spans[2].Tags)
+	}
+	if d := t1.Spans[1].Duration; d != 4000 {
+		t.Errorf("GetCart lasts %d µs, want 4000", d)
+	}
+
+	// a parent outside the export leaves the span a root
+	if span := traces.Data[1].Spans[0]; span.ParentService != "none" || span.StartTime != 5000 {
+		t.Errorf("orphan span = %+v", span)
+	}
+	if traces.AverageDuration != (9000+2000)/2/1000 {
+		t.Errorf("average duration %d ms", traces.AverageDuration)
+	}
+}
+
+func TestReadOTLPErrors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"empty":      ``,
+		"no spans":   `{"resourceSpans":[]}`,
+		"no ids":     `{"resourceSpans":[{"scopeSpans":[{"spans":[{"name":"x"}]}]}]}`,
+		"bad time":   `{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"t","spanId":"s","startTimeUnixNano":"soon"}]}]}]}`,
+		"not a json": `resourceSpans`,
+	} {
+		if _, err := ReadOTLP(strings.NewReader(doc)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
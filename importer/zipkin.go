@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"optimizer/common"
+	"sort"
+)
+
+// zipkinSpan is a span of the Zipkin v2 JSON API.
+type zipkinSpan struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId"`
+	Name          string            `json:"name"`
+	Timestamp     int64             `json:"timestamp"` // µs since the epoch
+	Duration      int64             `json:"duration"`  // µs
+	Shared        bool              `json:"shared"`
+	Tags          map[string]string `json:"tags"`
+	LocalEndpoint struct {
+		ServiceName string `json:"serviceName"`
+	} `json:"localEndpoint"`
+}
+
+// ReadZipkin reads Zipkin v2 JSON from r: a list of spans, or a list of
+// traces as /api/v2/traces returns them. The service of a span is the
+// serviceName of its localEndpoint.
+//
+// A server span that shares its id with the client span calling it is given
+// an id of its own and becomes the child of the client span, so both keep
+// their place in the call tree; the spans of its service naming the shared
+// id as their parent become its children.
+func ReadZipkin(r io.Reader) (*common.TraceData, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	var spans []zipkinSpan
+	for _, item := range raw {
+		var trace []zipkinSpan
+		if err := json.Unmarshal(item, &trace); err == nil {
+			spans = append(spans, trace...)
+			continue
+		}
+		var span zipkinSpan
+		if err := json.Unmarshal(item, &span); err != nil {
+			return nil, err
+		}
+		spans = append(spans, span)
+	}
+
+	type sharedKey struct{ traceID, id, service string }
+	shared := make(map[sharedKey]bool)
+	for _, s := range spans {
+		if s.Shared {
+			shared[sharedKey{s.TraceID, s.ID, serviceOf(s)}] = true
+		}
+	}
+
+	b := newBuilder()
+	for _, s := range spans {
+		if s.TraceID == "" || s.ID == "" {
+			return nil, fmt.Errorf("span %q without a trace or span id", s.Name)
+		}
+		service := serviceOf(s)
+		id, parent := s.ID, s.ParentID
+		if s.Shared {
+			id, parent = s.ID+"/"+service, s.ID
+		} else if shared[sharedKey{s.TraceID, s.ParentID, service}] {
+			parent = s.ParentID + "/" + service
+		}
+		b.add(common.Span{
+			TraceID:       s.TraceID,
+			SpanID:        id,
+			OperationName: s.Name,
+			StartTime:     s.Timestamp,
+			Duration:      s.Duration,
+			Tags:          zipkinTags(s.Tags),
+		}, service, parent)
+	}
+	return b.traceData()
+}
+
+// serviceOf returns the service reporting s.
+func serviceOf(s zipkinSpan) string {
+	if s.LocalEndpoint.ServiceName == "" {
+		return unknownService
+	}
+	return s.LocalEndpoint.ServiceName
+}
+
+// zipkinTags returns tags as span tags, sorted by key.
+func zipkinTags(tags map[string]string) []common.KeyValue {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var kvs []common.KeyValue
+	for _, key := range keys {
+		kvs = append(kvs, common.KeyValue{Key: key, Type: "string", Value: tags[key]})
+	}
+	return kvs
+}
@@ -0,0 +1,67 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+// frontend calls cart over RPC: the client span of frontend and the server
+// span of cart share the id "b", as B3 propagation makes them.
+const zipkinSpans = `[
+ {"traceId":"t1","id":"a","name":"get /cart","timestamp":1000,"duration":8000,"localEndpoint":{"serviceName":"frontend"}},
+ {"traceId":"t1","id":"b","parentId":"a","name":"getcart","kind":"CLIENT","timestamp":2000,"duration":5000,"localEndpoint":{"serviceName":"frontend"}},
+ {"traceId":"t1","id":"b","parentId":"a","name":"/cart/getcart","kind":"SERVER","shared":true,"timestamp":2500,"duration":4000,"localEndpoint":{"serviceName":"cart"}},
+ {"traceId":"t1","id":"c","parentId":"b","name":"hget","timestamp":3000,"duration":1000,"localEndpoint":{"serviceName":"cart"},"tags":{"db.system":"redis"}}
+]`
+
+func TestReadZipkin(t *testing.T) {
+	traces, err := ReadZipkin(strings.NewReader(zipkinSpans))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces.Data) != 1 || len(traces.Data[0].Spans) != 4 {
+		t.Fatalf("traces = %+v", traces.Data)
+	}
+	trace := traces.Data[0]
+	if trace.Duration != 8000 {
+		t.Errorf("trace lasts %d µs, want 8000", trace.Duration)
+	}
+	want := []struct{ service, parentService, parentOperation string }{
+		{"frontend", "none", "none"},
+		{"frontend", "frontend", "get /cart"},
+		{"cart", "frontend", "getcart"},
+		{"cart", "cart", "/cart/getcart"}, // under the server span of cart, not the client span "b"
+	}
+	for i, w := range want {
+		span := trace.Spans[i]
+		if span.ServiceName != w.service || span.ParentService != w.parentService || span.ParentOperation != w.parentOperation {
+			t.Errorf("span %s: %s called by %s/%s, want %s called by %s/%s", span.SpanID,
+				span.ServiceName, span.ParentService, span.ParentOperation, w.service, w.parentService, w.parentOperation)
+		}
+	}
+	if v, ok := trace.Spans[3].Tag("db.system"); !ok || v != "redis" {
+		t.Errorf("db.system = %q, %v", v, ok)
+	}
+}
+
+func TestReadZipkinTraces(t *testing.T) {
+	doc := `[[{"traceId":"t1","id":"a","name":"x","timestamp":1,"duration":2,"localEndpoint":{"serviceName":"s"}}],
+	         [{"traceId":"t2","id":"a","name":"y","timestamp":5,"duration":3}]]`
+	traces, err := ReadZipkin(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(traces.Data) != 2 || traces.Data[1].Spans[0].ServiceName != unknownService {
+		t.Errorf("traces = %+v", traces.Data)
+	}
+
+	for name, doc := range map[string]string{
+		"object": `{"traceId":"t1"}`,
+		"no ids": `[{"name":"x"}]`,
+		"empty":  `[]`,
+	} {
+		if _, err := ReadZipkin(strings.NewReader(doc)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Package jaeger queries the HTTP API of a Jaeger query service.
+package jaeger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"optimizer/common"
+	"strconv"
+	"time"
+)
+
+// Config describes how to reach a Jaeger query service.
+type Config struct {
+	BaseURL   string        // API root, e.g. http://localhost:16686/api
+	Token     string        // bearer token, if set
+	Username  string        // basic auth user, if set and Token is not
+	Password  string        // basic auth password
+	Timeout   time.Duration // per request; 0 means no limit
+	Retries   int           // extra attempts after a network error, 429 or 5xx
+	RetryWait time.Duration // wait before the first retry, doubled for each further one
+}
+
+// DefaultConfig returns the settings of a local all-in-one Jaeger.
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:   "http://localhost:16686/api",
+		Timeout:   30 * time.Second,
+		Retries:   2,
+		RetryWait: 500 * time.Millisecond,
+	}
+}
+
+// Client queries one Jaeger query service.
+type Client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// New returns a client for the service described by cfg.
+func New(cfg Config) *Client {
+	return &Client{cfg: cfg, http: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// response is the envelope of every Jaeger API answer.
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"errors"`
+}
+
+// Services returns the services that reported spans.
+func (c *Client) Services(ctx context.Context) ([]string, error) {
+	var services []string
+	err := c.get(ctx, "/services", nil, &services)
+	return services, err
+}
+
+// Operations returns the operations of service.
+func (c *Client) Operations(ctx context.Context, service string) ([]string, error) {
+	var operations []string
+	err := c.get(ctx, "/services/"+url.PathEscape(service)+"/operations", nil, &operations)
+	return operations, err
+}
+
+// Query selects traces.
+type Query struct {
+	Service   string
+	Operation string        // optional
+	Start     time.Time     // zero means End minus Lookback
+	End       time.Time     // zero means now
+	Lookback  time.Duration // used when Start is zero
+	Limit     int           // most traces per request; 0 leaves the server default
+	Window    time.Duration // split [Start, End) into windows of this length, one request each; 0 means one request
+}
+
+// Traces returns the traces matching q. With a Window, every window is
+// fetched in turn, so Limit applies per window; a trace spanning two windows
+// is kept once.
+func (c *Client) Traces(ctx context.Context, q Query) (*common.TraceData, error) {
+	end := q.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := q.Start
+	if start.IsZero() {
+		start = end.Add(-q.Lookback)
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("empty time range %s - %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	var traces common.TraceData
+	seen := make(map[string]bool)
+	for from := start; from.Before(end); {
+		to := end
+		if q.Window > 0 && from.Add(q.Window).Before(end) {
+			to = from.Add(q.Window)
+		}
+
+		params := url.Values{}
+		params.Set("service", q.Service)
+		if q.Operation != "" {
+			params.Set("operation", q.Operation)
+		}
+		params.Set("start", strconv.FormatInt(from.UnixMicro(), 10))
+		params.Set("end", strconv.FormatInt(to.UnixMicro(), 10))
+		if q.Limit > 0 {
+			params.Set("limit", strconv.Itoa(q.Limit))
+		}
+
+		var window []common.Trace
+		if err := c.get(ctx, "/traces", params, &window); err != nil {
+			return nil, fmt.Errorf("traces of %s from %s: %w", q.Service, from.Format(time.RFC3339), err)
+		}
+		for _, trace := range window {
+			if trace.TraceID != "" && seen[trace.TraceID] {
+				continue
+			}
+			seen[trace.TraceID] = true
+			traces.Data = append(traces.Data, trace)
+		}
+		from = to
+	}
+	return &traces, nil
+}
+
+// get fetches path with params and decodes the data of the answer into
+// data, retrying as configured.
+func (c *Client) get(ctx context.Context, path string, params url.Values, data any) error {
+	u := c.cfg.BaseURL + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	wait := c.cfg.RetryWait
+	for attempt := 0; ; attempt++ {
+		body, retry, err := c.do(ctx, u)
+		if err == nil {
+			var resp response
+			if err := json.Unmarshal(body, &resp); err != nil {
+				return fmt.Errorf("decoding %s: %w", path, err)
+			}
+			if len(resp.Errors) > 0 {
+				return fmt.Errorf("%s: jaeger error %d: %s", path, resp.Errors[0].Code, resp.Errors[0].Msg)
+			}
+			if len(resp.Data) == 0 || string(resp.Data) == "null" {
+				return nil
+			}
+			if err := json.Unmarshal(resp.Data, data); err != nil {
+				return fmt.Errorf("decoding %s: %w", path, err)
+			}
+			return nil
+		}
+		if !retry || attempt >= c.cfg.Retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// do sends one GET request to u and returns the body of a successful answer,
+// or whether a failure is worth retrying.
+func (c *Client) do(ctx context.Context, u string) ([]byte, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	} else if c.cfg.Username != "" {
+		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, true, fmt.Errorf("reading %s: %w", u, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return nil, retry, fmt.Errorf("GET %s: %s: %s", u, resp.Status, snippet(body))
+	}
+	return body, false, nil
+}
+
+// snippet returns the start of body for error messages.
+func snippet(body []byte) string {
+	const n = 200
+	if len(body) > n {
+		return string(body[:n]) + "..."
+	}
+	return string(body)
+}
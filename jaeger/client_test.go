@@ -0,0 +1,201 @@
+package jaeger
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeJaeger serves the parts of the Jaeger query API the client uses, after
+// handle, if set, had the chance to answer first. Every /traces request
+// answers with one trace named after its start time, plus a trace "shared"
+// that every window returns.
+func fakeJaeger(t *testing.T, handle func(w http.ResponseWriter, r *http.Request) bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if handle != nil && handle(w, r) {
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/services":
+			fmt.Fprint(w, `{"data":["frontend","cartservice"]}`)
+		case r.URL.Path == "/api/services/frontend/operations":
+			fmt.Fprint(w, `{"data":["GET /","GET /cart"]}`)
+		case r.URL.Path == "/api/traces":
+			q := r.URL.Query()
+			fmt.Fprintf(w, `{"data":[{"traceID":"t%s","spans":[{"spanID":"a","operationName":"%s","duration":5}]},{"traceID":"shared"}]}`,
+				q.Get("start"), q.Get("operation"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testConfig(srv *httptest.Server) Config {
+	cfg := DefaultConfig()
+	cfg.BaseURL = srv.URL + "/api"
+	cfg.RetryWait = time.Millisecond
+	return cfg
+}
+
+func TestServicesAndOperations(t *testing.T) {
+	c := New(testConfig(fakeJaeger(t, nil)))
+	services, err := c.Services(context.Background())
+	if err != nil || strings.Join(services, ",") != "frontend,cartservice" {
+		t.Errorf("Services = %v, %v", services, err)
+	}
+	operations, err := c.Operations(context.Background(), "frontend")
+	if err != nil || strings.Join(operations, ",") != "GET /,GET /cart" {
+		t.Errorf("Operations = %v, %v", operations, err)
+	}
+}
+
+func TestTracesWindows(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	srv := fakeJaeger(t, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/api/traces" {
+			q := r.URL.Query()
+			mu.Lock()
+			requests = append(requests, q.Get("start")+"-"+q.Get("end")+" limit "+q.Get("limit"))
+			mu.Unlock()
+		}
+		return false
+	})
+	c := New(testConfig(srv))
+
+	end := time.UnixMicro(10_000_000)
+	traces, err := c.Traces(context.Background(), Query{
+		Service:   "frontend",
+		Operation: "GET /cart",
+		End:       end,
+		Lookback:  5 * time.Second,
+		Limit:     20,
+		Window:    2 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"5000000-7000000 limit 20",
+		"7000000-9000000 limit 20",
+		"9000000-10000000 limit 20",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(requests, "; ") != strings.Join(want, "; ") {
+		t.Errorf("requests %v, want %v", requests, want)
+	}
+	var ids []string
+	for _, trace := range traces.Data {
+		ids = append(ids, trace.TraceID)
+	}
+	if got := strings.Join(ids, ","); got != "t5000000,shared,t7000000,t9000000" {
+		t.Errorf("traces %s", got)
+	}
+	if op := traces.Data[0].Spans[0].OperationName; op != "GET /cart" {
+		t.Errorf("operation %q not passed on", op)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	auth := make(chan string, 1)
+	srv := fakeJaeger(t, func(w http.ResponseWriter, r *http.Request) bool {
+		auth <- r.Header.Get("Authorization")
+		return false
+	})
+
+	cfg := testConfig(srv)
+	cfg.Token = "secret"
+	if _, err := New(cfg).Services(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-auth; got != "Bearer secret" {
+		t.Errorf("bearer: Authorization %q", got)
+	}
+
+	cfg = testConfig(srv)
+	cfg.Username, cfg.Password = "user", "pass"
+	if _, err := New(cfg).Services(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-auth; !strings.HasPrefix(got, "Basic ") {
+		t.Errorf("basic: Authorization %q", got)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := fakeJaeger(t, func(w http.ResponseWriter, r *http.Request) bool {
+		if calls.Add(1) <= 2 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return true
+		}
+		return false
+	})
+
+	cfg := testConfig(srv)
+	if _, err := New(cfg).Services(context.Background()); err != nil {
+		t.Errorf("two failures within two retries: %v", err)
+	}
+
+	calls.Store(0)
+	cfg.Retries = 1
+	_, err := New(cfg).Services(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("two failures with one retry: %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("%d requests, want 2", n)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv := fakeJaeger(t, func(w http.ResponseWriter, r *http.Request) bool {
+		switch r.URL.Query().Get("service") {
+		case "missing":
+			http.Error(w, "no such service", http.StatusNotFound)
+		case "broken":
+			fmt.Fprint(w, `{"data":null,"errors":[{"code":400,"msg":"bad query"}]}`)
+		default:
+			return false
+		}
+		return true
+	})
+	c := New(testConfig(srv))
+
+	for service, want := range map[string]string{"missing": "404", "broken": "bad query"} {
+		_, err := c.Traces(context.Background(), Query{Service: service, Lookback: time.Minute})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: error %v, want it to mention %q", service, err, want)
+		}
+	}
+	if _, err := c.Traces(context.Background(), Query{Service: "frontend"}); err == nil {
+		t.Errorf("empty time range accepted")
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	srv := fakeJaeger(t, func(w http.ResponseWriter, r *http.Request) bool {
+		time.Sleep(200 * time.Millisecond)
+		return false
+	})
+	cfg := testConfig(srv)
+	cfg.Timeout = 20 * time.Millisecond
+	cfg.Retries = 0
+	start := time.Now()
+	if _, err := New(cfg).Services(context.Background()); err == nil {
+		t.Errorf("slow server did not time out")
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("gave up after %s", elapsed)
+	}
+}
@@ -1,11 +1,511 @@
 package main
 
-// import "fmt"
+import (
+	"context"
+	"flag"
+	"fmt"
+	"optimizer/algorithms"
+	"optimizer/analyzer"
+	"optimizer/common"
+	"optimizer/importer"
+	"optimizer/jaeger"
+	"optimizer/preprocess"
+	"optimizer/utils"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+const usage = `usage: optimizer <command> [subcommand] [flags]
+
+commands:
+  optimize                    search a deployment (--algo tigo|dpso|pso|gwo|ps-gwca)
+  collect traces              fetch and preprocess traces from Jaeger
+  collect processing-time     measure per-operation self durations from Jaeger
+  import otlp                 convert an OTLP/JSON trace export to a trace file
+  import zipkin               convert a Zipkin v2 JSON trace export to a trace file
+  preprocess                  preprocess a saved Jaeger query response offline
+  analyze depic               compute the DepIC heatmap of a trace file
+  analyze dependency          print invocation chains, direct call counts and DepICs
+  analyze instances           sum the replicas of each service in a solution
+  select                      pick a deployment from an exported Pareto front
+  apply                       apply a deployment config to the cluster
+
+Run "optimizer <command> [subcommand] -h" for the flags of a command.
+`
 
 func main() {
-	// GetTraceData("app.json")
-	// UpDateDeploymentsByJSON("deployment_config_example.json")
-	// GetProcessingTime("process_time_cloud.json")
-	// RunDPSO()
-	RunTIGO()
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "optimize":
+		err = runOptimize(args)
+	case "collect":
+		err = runCollect(args)
+	case "import":
+		err = runImport(args)
+	case "preprocess":
+		err = runPreprocess(args)
+	case "analyze":
+		err = runAnalyze(args)
+	case "select":
+		err = runSelect(args)
+	case "apply":
+		err = runApply(args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "optimizer:", err)
+		os.Exit(1)
+	}
+}
+
+// inputFlags registers the flags naming the input documents of a run.
+func inputFlags(fs *flag.FlagSet) *algorithms.Files {
+	files := algorithms.DefaultFiles()
+	fs.StringVar(&files.App, "app", files.App, "preprocessed trace file")
+	fs.StringVar(&files.ServiceResources, "service-resources", files.ServiceResources, "CPU/memory requests per service")
+	fs.StringVar(&files.NodeResources, "node-resources", files.NodeResources, "CPU/memory capacity per node")
+	fs.StringVar(&files.EdgeTimes, "edge-times", files.EdgeTimes, "processing time per operation on edge nodes")
+	fs.StringVar(&files.CloudTimes, "cloud-times", files.CloudTimes, "processing time per operation on cloud nodes")
+	fs.Func("node-times", "processing time table for one node or node class, as name=file (repeatable)", func(v string) error {
+		name, file, ok := strings.Cut(v, "=")
+		if !ok || name == "" || file == "" {
+			return fmt.Errorf("want name=file, got %q", v)
+		}
+		if files.NodeTimes == nil {
+			files.NodeTimes = make(map[string]string)
+		}
+		files.NodeTimes[name] = file
+		return nil
+	})
+	fs.StringVar(&files.DepICs, "depics", files.DepICs, "DepIC heatmap CSV (empty: no co-location term)")
+	fs.StringVar(&files.SLOs, "slo", files.SLOs, "latency targets in ms per root operation (empty: none)")
+	fs.StringVar(&files.Placement, "placement", files.Placement, "placement rules: pin, forbid, colocate, spread, replicas, locality (see placement_example.json)")
+	fs.StringVar(&files.Power, "power", files.Power, "power profile: idle and per-core watts per node (empty: none)")
+	fs.StringVar(&files.Cost, "cost", files.Cost, "cost profile: price per millicore/MiB-hour per node and egress per GB between tiers (empty: none)")
+	fs.StringVar(&files.Network, "network", files.Network, "network profile: link latency/bandwidth and call payloads (empty: flat 50 ms per hop)")
+	return &files
+}
+
+// selectionFlags registers the flags choosing a deployment from a front.
+// The returned function builds the selection once the flags are parsed.
+func selectionFlags(fs *flag.FlagSet) func() (algorithms.Selection, error) {
+	by := fs.String("select", "best", "how to pick from the front: best, knee, weighted or bounded")
+	weights := fs.String("weights", "", "weighted: objective weights, e.g. latency=1,cost=0.5")
+	minimize := fs.String("minimize", "latency", "bounded: objective to minimize")
+	limits := fs.String("limits", "", "bounded: objective upper bounds, e.g. cost=0.5")
+	return func() (algorithms.Selection, error) {
+		selection := algorithms.Selection{By: *by, Minimize: *minimize}
+		var err error
+		if selection.Weights, err = algorithms.ParseObjectiveValues(*weights); err != nil {
+			return selection, fmt.Errorf("--weights: %w", err)
+		}
+		if selection.Limits, err = algorithms.ParseObjectiveValues(*limits); err != nil {
+			return selection, fmt.Errorf("--limits: %w", err)
+		}
+		return selection, nil
+	}
+}
+
+// jaegerFlags registers the flags reaching the Jaeger query service. The
+// returned function builds the client once the flags are parsed.
+func jaegerFlags(fs *flag.FlagSet) func() *jaeger.Client {
+	cfg := jaeger.DefaultConfig()
+	fs.StringVar(&cfg.BaseURL, "jaeger", cfg.BaseURL, "Jaeger query API root")
+	fs.StringVar(&cfg.Token, "jaeger-token", "", "bearer token for the Jaeger API")
+	fs.StringVar(&cfg.Username, "jaeger-user", "", "basic auth user for the Jaeger API")
+	fs.StringVar(&cfg.Password, "jaeger-password", "", "basic auth password for the Jaeger API")
+	fs.DurationVar(&cfg.Timeout, "jaeger-timeout", cfg.Timeout, "timeout of one Jaeger request (0: none)")
+	fs.IntVar(&cfg.Retries, "jaeger-retries", cfg.Retries, "retries of a Jaeger request after a network error, 429 or 5xx")
+	return func() *jaeger.Client {
+		return jaeger.New(cfg)
+	}
+}
+
+// preprocessFlags registers the flags choosing the optional preprocessing
+// stages. The returned function builds them once the flags are parsed.
+func preprocessFlags(fs *flag.FlagSet) func() []preprocess.Stage {
+	dedupe := fs.Bool("dedupe", false, "keep one trace per trace ID")
+	var rootOps []string
+	fs.Func("root-op", "keep only traces with this root operation (repeatable)", func(v string) error {
+		rootOps = append(rootOps, v)
+		return nil
+	})
+	dropHealth := fs.Bool("drop-health-checks", false, "drop traces of health and readiness probes")
+	remap := make(map[string]string)
+	fs.Func("remap", "rename a service, as from=to (repeatable)", func(v string) error {
+		from, to, ok := strings.Cut(v, "=")
+		if !ok || from == "" || to == "" {
+			return fmt.Errorf("want from=to, got %q", v)
+		}
+		remap[from] = to
+		return nil
+	})
+	return func() []preprocess.Stage {
+		var stages []preprocess.Stage
+		if *dedupe {
+			stages = append(stages, preprocess.Dedupe)
+		}
+		if len(rootOps) > 0 {
+			stages = append(stages, preprocess.FilterRootOperations(rootOps...))
+		}
+		if *dropHealth {
+			stages = append(stages, preprocess.DropRootOperations(preprocess.HealthChecks))
+		}
+		if len(remap) > 0 {
+			stages = append(stages, preprocess.RemapServices(remap))
+		}
+		return stages
+	}
+}
+
+// serviceRulesFlag registers the flag naming the service rules file. The
+// returned function loads the rules once the flags are parsed, or returns nil
+// if the flag is empty or the default file does not exist.
+func serviceRulesFlag(fs *flag.FlagSet) func() (*common.ServiceRules, error) {
+	const name = "service-rules"
+	filename := fs.String(name, "service_rules.json", "rules attributing spans to virtual services by operation or tag (empty: none; skipped if the default is missing; see service_rules_example.json)")
+	return func() (*common.ServiceRules, error) {
+		if *filename == "" {
+			return nil, nil
+		}
+		explicit := false
+		fs.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == name })
+		if _, err := os.Stat(*filename); !explicit && os.IsNotExist(err) {
+			return nil, nil
+		}
+		return common.LoadServiceRules(*filename)
+	}
+}
+
+// topologyFlag registers the flag naming the topology file.
+func topologyFlag(fs *flag.FlagSet) *string {
+	return fs.String("topology", "topology.json", "nodes, services and call graph of the application")
+}
+
+func runOptimize(args []string) error {
+	fs := flag.NewFlagSet("optimize", flag.ExitOnError)
+	algo := fs.String("algo", "tigo", fmt.Sprintf("algorithm: one of %v", algorithms.Names()))
+	topologyFile := topologyFlag(fs)
+	files := inputFlags(fs)
+	output := fs.String("output", "", "solution file (default <algo>_solution.json)")
+	loadFactor := fs.Float64("load-factor", 1, "scale the request rate observed in the traces, e.g. 10 for ten times the traffic")
+	spendWeight := fs.Float64("spend-weight", 0, "weight of the spend per hour in the fitness, trading latency in ms against cost")
+	energyWeight := fs.Float64("energy-weight", 0, "weight of the energy in Wh per hour in the fitness, trading latency in ms against power")
+	infeasible := fs.String("infeasible", "penalty", "handling of deployments breaking a constraint: penalty (flat), graded (grows with the violation) or repair (fix them as they are built)")
+	latency := fs.String("latency", "mean", "latency to minimize: mean, a percentile per root operation such as p95, or slo (% of traces missing their target)")
+	resultFile := fs.String("result", "", "write the full result (score, history, elapsed time) to this file")
+	var opts algorithms.Options
+	fs.IntVar(&opts.Iterations, "iterations", 100, "iterations (dpso, pso, gwo, ps-gwca)")
+	fs.IntVar(&opts.Particles, "particles", 0, "population size (default 30 for dpso, 300 for pso, gwo and ps-gwca)")
+	fs.IntVar(&opts.BranchSize, "branch-size", 5, "branch search size (tigo)")
+	fs.StringVar(&opts.HistoryFile, "history", "dpso_optimization_results.csv", "per-iteration best score CSV (dpso)")
+	fs.IntVar(&opts.Workers, "workers", 0, "concurrent fitness evaluations (dpso, pso, gwo, ps-gwca; default: number of CPUs)")
+	fs.Int64Var(&opts.Seed, "seed", 0, "random seed; reuse the seed of a result to replay it (0: from the clock)")
+	fs.DurationVar(&opts.Timeout, "timeout", 0, "stop after this long and keep the best solution so far (0: no limit)")
+	frontJSON := fs.String("front-json", "", "write the final Pareto front to this JSON file (pso, gwo, ps-gwca)")
+	frontCSV := fs.String("front-csv", "", "write the final Pareto front to this CSV file (pso, gwo, ps-gwca)")
+	selection := selectionFlags(fs)
+	fs.Parse(args)
+	sel, err := selection()
+	if err != nil {
+		return err
+	}
+
+	optimizer, err := algorithms.New(*algo, opts)
+	if err != nil {
+		return err
+	}
+	topo, err := common.LoadTopology(*topologyFile)
+	if err != nil {
+		return err
+	}
+	problem, err := algorithms.LoadProblem(*files, topo)
+	if err != nil {
+		return err
+	}
+	problem.LoadFactor = *loadFactor
+	problem.SpendWeight = *spendWeight
+	problem.EnergyWeight = *energyWeight
+	if err := problem.SetLatencyObjective(*latency); err != nil {
+		return err
+	}
+	if err := problem.SetInfeasibleMode(*infeasible); err != nil {
+		return err
+	}
+
+	// Ctrl-C stops the search; the best solution so far is still written.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := optimizer.Optimize(ctx, problem)
+	if err != nil {
+		return err
+	}
+	if result.Stopped != "" {
+		fmt.Printf("stopped early (%s), keeping the best solution found so far\n", result.Stopped)
+	}
+	if *output == "" {
+		*output = fmt.Sprintf("%s_solution.json", *algo)
+	}
+	if *frontJSON != "" {
+		if err := algorithms.WriteFrontJSON(*frontJSON, result.Front); err != nil {
+			return fmt.Errorf("writing %s: %w", *frontJSON, err)
+		}
+	}
+	if *frontCSV != "" {
+		if err := algorithms.WriteFrontCSV(*frontCSV, result.Front); err != nil {
+			return fmt.Errorf("writing %s: %w", *frontCSV, err)
+		}
+	}
+	solution := result.BestSolution
+	if sel.By != "best" {
+		if len(result.Front) == 0 {
+			return fmt.Errorf("--select %s: %s does not produce a Pareto front", sel.By, result.Algorithm)
+		}
+		i, err := sel.Pick(result.Front)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("selected front member %d of %d (%s): %v\n", i, len(result.Front), sel.By, result.Front[i].Objectives)
+		solution = result.Front[i].Solution
+	}
+	fmt.Println("Best Solution:")
+	common.PrintJSON(solution, *output)
+	if *resultFile != "" {
+		common.PrintJSON(result, *resultFile)
+	}
+	fmt.Printf("best score(%s): %f\n", result.Algorithm, result.BestScore)
+	fmt.Printf("seed(%s): %d\n", result.Algorithm, result.Seed)
+	if len(result.Front) > 0 {
+		fmt.Printf("pareto front(%s): %d members\n", result.Algorithm, len(result.Front))
+	}
+	printLatencyReport(problem.LatencyReport(solution))
+	printViolations(problem.Violations(solution))
+	objectives, _ := problem.Objectives(solution)
+	if problem.Cost != nil {
+		fmt.Printf("spend(%s): %.4f per hour\n", result.Algorithm, objectives[algorithms.ObjSpend])
+	}
+	if problem.Power != nil {
+		fmt.Printf("energy(%s): %.1f Wh per hour\n", result.Algorithm, objectives[algorithms.ObjEnergy])
+	}
+	fmt.Printf("execution time(%s): %s\n\n", result.Algorithm, result.Elapsed)
+	return nil
+}
+
+// printLatencyReport prints the predicted latency of each root operation.
+func printLatencyReport(report []algorithms.EndpointLatency) {
+	fmt.Println("predicted latency by root operation (ms):")
+	for _, e := range report {
+		fmt.Printf("  %s: %d traces, mean %.1f, p50 %.1f, p95 %.1f, p99 %.1f", e.Operation, e.Traces, e.Mean, e.P50, e.P95, e.P99)
+		if e.Target > 0 {
+			fmt.Printf(", %.1f%% over the %.0f ms target", 100*e.Missed, e.Target)
+		}
+		fmt.Println()
+	}
+}
+
+// printViolations prints the constraints a solution breaks.
+func printViolations(violations []algorithms.Violation) {
+	if len(violations) == 0 {
+		fmt.Println("constraints: all satisfied")
+		return
+	}
+	fmt.Printf("constraints: %d violated\n", len(violations))
+	for _, v := range violations {
+		fmt.Printf("  %s\n", v)
+	}
+}
+
+func runCollect(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("collect: expected traces or processing-time")
+	}
+	switch sub, args := args[0], args[1:]; sub {
+	case "traces":
+		fs := flag.NewFlagSet("collect traces", flag.ExitOnError)
+		output := fs.String("output", "app.json", "trace file to write")
+		client := jaegerFlags(fs)
+		var q jaeger.Query
+		fs.StringVar(&q.Service, "service", "frontend", "service whose traces are fetched")
+		fs.StringVar(&q.Operation, "operation", "", "only traces through this operation of the service")
+		fs.DurationVar(&q.Lookback, "lookback", time.Minute, "how far back to fetch traces")
+		fs.IntVar(&q.Limit, "limit", 0, "most traces per request (0: the Jaeger default)")
+		fs.DurationVar(&q.Window, "window", 0, "fetch the lookback in windows of this length, one request each (0: one request)")
+		stages := preprocessFlags(fs)
+		loadRules := serviceRulesFlag(fs)
+		fs.Parse(args)
+		rules, err := loadRules()
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return GetTraceData(ctx, client(), q, *output, rules, stages()...)
+	case "processing-time":
+		fs := flag.NewFlagSet("collect processing-time", flag.ExitOnError)
+		output := fs.String("output", "processing_time_edge.json", "processing time file to write")
+		client := jaegerFlags(fs)
+		lookback := fs.Duration("lookback", time.Hour, "how far back to look for traces of each operation")
+		limit := fs.Int("limit", 500, "most traces per operation")
+		loadRules := serviceRulesFlag(fs)
+		fs.Parse(args)
+		rules, err := loadRules()
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return GetProcessingTime(ctx, client(), rules, *lookback, *limit, *output)
+	default:
+		return fmt.Errorf("collect: unknown subcommand %q", sub)
+	}
+}
+
+func runImport(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("import: expected one of %v", importer.Formats())
+	}
+	format, args := args[0], args[1:]
+	fs := flag.NewFlagSet("import "+format, flag.ExitOnError)
+	input := fs.String("input", "", "trace export to read")
+	output := fs.String("output", "app.json", "trace file to write")
+	stages := preprocessFlags(fs)
+	loadRules := serviceRulesFlag(fs)
+	fs.Parse(args)
+	if *input == "" {
+		return fmt.Errorf("import %s: --input is required", format)
+	}
+	rules, err := loadRules()
+	if err != nil {
+		return err
+	}
+	traceData, err := importer.Load(format, *input)
+	if err != nil {
+		return err
+	}
+	// the importers fill in callers and durations; only the services of
+	// rules and the averages change with the optional stages
+	var extra []preprocess.Stage
+	if rules != nil {
+		extra = append(extra, preprocess.ApplyServiceRules(rules))
+	}
+	extra = append(extra, stages()...)
+	printJSON(preprocess.Run(traceData, append(extra, common.CalculateAverageDuration)...), *output)
+	return nil
+}
+
+func runPreprocess(args []string) error {
+	fs := flag.NewFlagSet("preprocess", flag.ExitOnError)
+	input := fs.String("input", "", "Jaeger query response to read, as saved from /api/traces")
+	output := fs.String("output", "app.json", "trace file to write")
+	stages := preprocessFlags(fs)
+	loadRules := serviceRulesFlag(fs)
+	fs.Parse(args)
+	if *input == "" {
+		return fmt.Errorf("preprocess: --input is required")
+	}
+	rules, err := loadRules()
+	if err != nil {
+		return err
+	}
+	return Preprocess(*input, *output, rules, stages()...)
+}
+
+func runAnalyze(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("analyze: expected depic, dependency or instances")
+	}
+	switch sub, args := args[0], args[1:]; sub {
+	case "depic":
+		fs := flag.NewFlagSet("analyze depic", flag.ExitOnError)
+		app := fs.String("app", "app.json", "preprocessed trace file")
+		output := fs.String("output", "depICs.csv", "DepIC heatmap CSV to write")
+		topologyFile := topologyFlag(fs)
+		fs.Parse(args)
+		topo, err := common.LoadTopology(*topologyFile)
+		if err != nil {
+			return err
+		}
+		return analyzer.RunAnalyzer(*app, *output, topo)
+	case "dependency":
+		fs := flag.NewFlagSet("analyze dependency", flag.ExitOnError)
+		app := fs.String("app", "app.json", "preprocessed trace file")
+		topologyFile := topologyFlag(fs)
+		var pairs [][2]string
+		fs.Func("pair", "print the DepIC of this pair of services, as from,to (repeatable; default: every call of the topology)", func(v string) error {
+			from, to, ok := strings.Cut(v, ",")
+			if !ok || from == "" || to == "" {
+				return fmt.Errorf("want from,to, got %q", v)
+			}
+			pairs = append(pairs, [2]string{from, to})
+			return nil
+		})
+		fs.Parse(args)
+		topo, err := common.LoadTopology(*topologyFile)
+		if err != nil {
+			return err
+		}
+		for _, pair := range pairs {
+			for _, service := range pair {
+				if !topo.HasService(service) {
+					return fmt.Errorf("analyze dependency: unknown service %q", service)
+				}
+			}
+		}
+		return RunDependency(*app, topo, pairs)
+	case "instances":
+		fs := flag.NewFlagSet("analyze instances", flag.ExitOnError)
+		solution := fs.String("solution", "tigo_solution.json", "solution file")
+		fs.Parse(args)
+		sumServiceInstances(*solution)
+		return nil
+	default:
+		return fmt.Errorf("analyze: unknown subcommand %q", sub)
+	}
+}
+
+func runSelect(args []string) error {
+	fs := flag.NewFlagSet("select", flag.ExitOnError)
+	frontFile := fs.String("front", "ps-gwca_front.json", "Pareto front written by optimize --front-json")
+	output := fs.String("output", "selected_solution.json", "solution file to write")
+	selection := selectionFlags(fs)
+	fs.Parse(args)
+	sel, err := selection()
+	if err != nil {
+		return err
+	}
+	front, err := algorithms.LoadFront(*frontFile)
+	if err != nil {
+		return err
+	}
+	i, err := sel.Pick(front)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("selected front member %d of %d (%s): %v\n", i, len(front), sel.By, front[i].Objectives)
+	common.PrintJSON(front[i].Solution, *output)
+	return nil
+}
+
+func runApply(args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	config := fs.String("config", "deployment_config_example.json", "deployment config (solution) to apply")
+	kubeconfig := fs.String("kubeconfig", utils.DefaultKubeconfig(), "kubeconfig file")
+	topologyFile := topologyFlag(fs)
+	fs.Parse(args)
+	topo, err := common.LoadTopology(*topologyFile)
+	if err != nil {
+		return err
+	}
+	return utils.UpDateDeploymentsByJSON(*config, *kubeconfig, topo)
 }
@@ -0,0 +1,242 @@
+// Package preprocess prepares traces for the analyzer and the optimizers:
+// it fills in what the fitness needs, such as the caller of every span, and
+// filters out traces that should not count.
+package preprocess
+
+import (
+	"optimizer/common"
+	"regexp"
+)
+
+// Stage transforms traces in place and returns them.
+type Stage func(*common.TraceData) *common.TraceData
+
+// Run applies stages to traces in order.
+func Run(traces *common.TraceData, stages ...Stage) *common.TraceData {
+	for _, stage := range stages {
+		traces = stage(traces)
+	}
+	return traces
+}
+
+// Default returns the stages turning a Jaeger query response into a trace
+// file, with extra run once every span knows its service and caller and
+// before the durations are computed. rules, if not nil, attribute spans to
+// virtual services.
+func Default(rules *common.ServiceRules, extra ...Stage) []Stage {
+	stages := []Stage{PopulateParentFields, SetServiceNames}
+	if rules != nil {
+		stages = append(stages, ApplyServiceRules(rules))
+	}
+	stages = append(stages, extra...)
+	return append(stages, SetTraceDurations, common.CalculateAverageDuration)
+}
+
+// parentOf returns the parent of span in spans, and false if it has none
+// among them.
+func parentOf(span common.Span, spans map[string]common.Span) (common.Span, bool) {
+	for _, ref := range span.References {
+		if ref.RefType == common.RefChildOf {
+			parent, ok := spans[ref.SpanID]
+			return parent, ok
+		}
+	}
+	return common.Span{}, false
+}
+
+// PopulateParentFields fills in ParentService and ParentOperation for each
+// span, "none" for root spans, and sets the duration of each trace to that
+// of its longest root span.
+func PopulateParentFields(traces *common.TraceData) *common.TraceData {
+	for i, trace := range traces.Data {
+		// Build a map of spanID to Span for parent lookup
+		spanMap := make(map[string]common.Span)
+		for _, span := range trace.Spans {
+			spanMap[span.SpanID] = span
+		}
+
+		var totalDuration int64
+		for _, span := range trace.Spans {
+			if !hasParent(span) && span.Duration > totalDuration {
+				totalDuration = span.Duration
+			}
+		}
+		traces.Data[i].Duration = totalDuration
+		traces.Data[i].PredictedDuration = 0 // Default to 0 as not provided by Jaeger
+
+		// Update spans with parent information
+		for j, span := range trace.Spans {
+			parentService, parentOperation := "none", "none"
+			if parent, ok := parentOf(span, spanMap); ok {
+				parentService = trace.Processes[parent.ProcessID].ServiceName
+				parentOperation = parent.OperationName
+			}
+			traces.Data[i].Spans[j].ParentService = parentService
+			traces.Data[i].Spans[j].ParentOperation = parentOperation
+		}
+	}
+	return traces
+}
+
+// hasParent reports whether span names a parent.
+func hasParent(span common.Span) bool {
+	for _, ref := range span.References {
+		if ref.RefType == common.RefChildOf {
+			return true
+		}
+	}
+	return false
+}
+
+// SetServiceNames sets the ServiceName of each span to that of the process
+// reporting it.
+func SetServiceNames(traces *common.TraceData) *common.TraceData {
+	for i := range traces.Data {
+		for j := range traces.Data[i].Spans {
+			if process, ok := traces.Data[i].Processes[traces.Data[i].Spans[j].ProcessID]; ok {
+				traces.Data[i].Spans[j].ServiceName = process.ServiceName
+			}
+		}
+	}
+	return traces
+}
+
+// ApplyServiceRules attributes the spans rules match to the service they
+// name, and makes that service the caller of their children.
+func ApplyServiceRules(rules *common.ServiceRules) Stage {
+	return func(traces *common.TraceData) *common.TraceData {
+		for i := range traces.Data {
+			spans := traces.Data[i].Spans
+			remapped := make(map[string]string)
+			for j := range spans {
+				if service, ok := rules.ServiceOf(spans[j]); ok {
+					spans[j].ServiceName = service
+					remapped[spans[j].SpanID] = service
+				}
+			}
+			if len(remapped) == 0 {
+				continue
+			}
+			for j := range spans {
+				for _, ref := range spans[j].References {
+					if service, ok := remapped[ref.SpanID]; ok && ref.RefType == common.RefChildOf {
+						spans[j].ParentService = service
+					}
+				}
+			}
+		}
+		return traces
+	}
+}
+
+// SetTraceDurations sets the duration of each trace to the time from its
+// first span start to its last span end.
+func SetTraceDurations(traces *common.TraceData) *common.TraceData {
+	for i := range traces.Data {
+		earliestStart := int64(1<<63 - 1)
+		latestEnd := int64(0)
+		for _, span := range traces.Data[i].Spans {
+			earliestStart = min(earliestStart, span.StartTime)
+			latestEnd = max(latestEnd, span.StartTime+span.Duration)
+		}
+		traces.Data[i].Duration = latestEnd - earliestStart
+	}
+	return traces
+}
+
+// RootOperation returns the operation of the root span of trace: the
+// earliest span without a parent in the trace, or "" for an empty trace.
+func RootOperation(trace common.Trace) string {
+	spans := make(map[string]common.Span, len(trace.Spans))
+	for _, span := range trace.Spans {
+		spans[span.SpanID] = span
+	}
+	var root *common.Span
+	for i, span := range trace.Spans {
+		if _, ok := parentOf(span, spans); ok {
+			continue
+		}
+		if root == nil || span.StartTime < root.StartTime {
+			root = &trace.Spans[i]
+		}
+	}
+	if root == nil {
+		return ""
+	}
+	return root.OperationName
+}
+
+// keep returns a stage keeping the traces for which ok holds.
+func keep(ok func(common.Trace) bool) Stage {
+	return func(traces *common.TraceData) *common.TraceData {
+		kept := traces.Data[:0]
+		for _, trace := range traces.Data {
+			if ok(trace) {
+				kept = append(kept, trace)
+			}
+		}
+		traces.Data = kept
+		return traces
+	}
+}
+
+// FilterRootOperations keeps the traces whose root operation is one of
+// operations.
+func FilterRootOperations(operations ...string) Stage {
+	wanted := make(map[string]bool, len(operations))
+	for _, op := range operations {
+		wanted[op] = true
+	}
+	return keep(func(trace common.Trace) bool {
+		return wanted[RootOperation(trace)]
+	})
+}
+
+// HealthChecks matches the root operations of the usual liveness and
+// readiness probes.
+var HealthChecks = regexp.MustCompile(`(?i)health|readyz|livez|(^|/)(ready|live|ping)$`)
+
+// DropRootOperations drops the traces whose root operation matches re, such
+// as HealthChecks.
+func DropRootOperations(re *regexp.Regexp) Stage {
+	return keep(func(trace common.Trace) bool {
+		return !re.MatchString(RootOperation(trace))
+	})
+}
+
+// Dedupe drops every trace but the first with the same trace ID, as
+// overlapping query windows or concatenated dumps produce.
+func Dedupe(traces *common.TraceData) *common.TraceData {
+	seen := make(map[string]bool, len(traces.Data))
+	return keep(func(trace common.Trace) bool {
+		if seen[trace.TraceID] {
+			return false
+		}
+		seen[trace.TraceID] = true
+		return true
+	})(traces)
+}
+
+// RemapServices renames services by names: in processes, spans and callers.
+// Services mapped to the same name merge.
+func RemapServices(names map[string]string) Stage {
+	rename := func(service string) string {
+		if to, ok := names[service]; ok {
+			return to
+		}
+		return service
+	}
+	return func(traces *common.TraceData) *common.TraceData {
+		for i, trace := range traces.Data {
+			for id, process := range trace.Processes {
+				trace.Processes[id] = common.Process{ServiceName: rename(process.ServiceName)}
+			}
+			for j := range trace.Spans {
+				span := &traces.Data[i].Spans[j]
+				span.ServiceName = rename(span.ServiceName)
+				span.ParentService = rename(span.ParentService)
+			}
+		}
+		return traces
+	}
+}
@@ -0,0 +1,169 @@
+package preprocess
+
+import (
+	"encoding/json"
+	"optimizer/common"
+	"reflect"
+	"testing"
+)
+
+// A Jaeger query response: a page view, the same trace again from an
+// overlapping window, and a readiness probe.
+const jaegerDump = `{"data":[
+ {"traceID":"t1","processes":{"p1":{"serviceName":"frontend"},"p2":{"serviceName":"cart"}},"spans":[
+  {"spanID":"a","operationName":"GET /cart","startTime":1000,"duration":5000,"processID":"p1","references":[]},
+  {"spanID":"b","operationName":"GetCart","startTime":2000,"duration":3000,"processID":"p2","references":[{"refType":"CHILD_OF","spanID":"a"}]},
+  {"spanID":"c","operationName":"RedisGetCart","startTime":2500,"duration":4000,"processID":"p2","references":[{"refType":"CHILD_OF","spanID":"b"}]}]},
+ {"traceID":"t1","processes":{"p1":{"serviceName":"frontend"}},"spans":[
+  {"spanID":"a","operationName":"GET /cart","startTime":1000,"duration":5000,"processID":"p1","references":[]}]},
+ {"traceID":"t2","processes":{"p1":{"serviceName":"frontend"}},"spans":[
+  {"spanID":"x","operationName":"GET /_healthz","startTime":9000,"duration":1000,"processID":"p1","references":[]}]}
+]}`
+
+func loadRules(t *testing.T) *common.ServiceRules {
+	t.Helper()
+	rules, err := common.LoadServiceRules("../service_rules.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func loadDump(t *testing.T) *common.TraceData {
+	t.Helper()
+	var traces common.TraceData
+	if err := json.Unmarshal([]byte(jaegerDump), &traces); err != nil {
+		t.Fatal(err)
+	}
+	return &traces
+}
+
+func traceIDs(traces *common.TraceData) []string {
+	var ids []string
+	for _, trace := range traces.Data {
+		ids = append(ids, trace.TraceID)
+	}
+	return ids
+}
+
+func TestDefault(t *testing.T) {
+	traces := Run(loadDump(t), Default(loadRules(t))...)
+	trace := traces.Data[0]
+	want := [][3]string{
+		{"frontend", "none", "none"},
+		{"cart", "frontend", "GET /cart"},
+		{"redis-cart", "cart", "GetCart"},
+	}
+	for i, w := range want {
+		span := trace.Spans[i]
+		if got := [3]string{span.ServiceName, span.ParentService, span.ParentOperation}; got != w {
+			t.Errorf("span %s = %v, want %v", span.SpanID, got, w)
+		}
+	}
+	if trace.Duration != 5500 { // 1 ms to 6.5 ms
+		t.Errorf("trace lasts %d µs, want 5500", trace.Duration)
+	}
+	if traces.AverageDuration != (5500+5000+1000)/3/1000 {
+		t.Errorf("average duration %d ms", traces.AverageDuration)
+	}
+}
+
+func TestStages(t *testing.T) {
+	for name, test := range map[string]struct {
+		stage Stage
+		want  []string
+	}{
+		"dedupe":        {Dedupe, []string{"t1", "t2"}},
+		"root op":       {FilterRootOperations("GET /cart"), []string{"t1", "t1"}},
+		"health checks": {DropRootOperations(HealthChecks), []string{"t1", "t1"}},
+	} {
+		if got := traceIDs(Run(loadDump(t), Default(nil, test.stage)...)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: traces %v, want %v", name, got, test.want)
+		}
+	}
+
+	traces := Run(loadDump(t), Default(nil, RemapServices(map[string]string{"cart": "carts"}))...)
+	if span := traces.Data[0].Spans[2]; span.ParentService != "carts" || traces.Data[0].Spans[1].ServiceName != "carts" {
+		t.Errorf("cart not renamed: %+v", traces.Data[0].Spans)
+	}
+	if traces.Data[0].Processes["p2"].ServiceName != "carts" {
+		t.Errorf("process not renamed: %v", traces.Data[0].Processes)
+	}
+}
+
+func TestServiceRules(t *testing.T) {
+	rules, err := common.NewServiceRules(
+		common.ServiceRule{Tag: "db.system", Value: "^redis$", Service: "cache"},
+		common.ServiceRule{Tag: "peer.service"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traces := loadDump(t)
+	spans := traces.Data[0].Spans
+	spans[1].Tags = []common.KeyValue{{Key: "peer.service", Type: "string", Value: "carts"}}
+	spans[2].Tags = []common.KeyValue{{Key: "db.system", Type: "string", Value: "redis"}}
+	traces = Run(traces, Default(rules)...)
+
+	want := [][3]string{
+		{"frontend", "none", "none"},
+		{"carts", "frontend", "GET /cart"},
+		{"cache", "carts", "GetCart"},
+	}
+	for i, w := range want {
+		span := traces.Data[0].Spans[i]
+		if got := [3]string{span.ServiceName, span.ParentService, span.ParentOperation}; got != w {
+			t.Errorf("span %s = %v, want %v", span.SpanID, got, w)
+		}
+	}
+
+	for _, bad := range []common.ServiceRule{
+		{Service: "x"},
+		{Operation: "^Get"},
+		{Operation: "(", Service: "x"},
+		{Tag: "db.system", Value: "["},
+	} {
+		if _, err := common.NewServiceRules(bad); err == nil {
+			t.Errorf("rule %+v accepted", bad)
+		}
+	}
+}
+
+func TestHealthChecks(t *testing.T) {
+	for op, want := range map[string]bool{
+		"/healthz":                     true,
+		"GET /health":                  true,
+		"grpc.health.v1.Health/Check":  true,
+		"/readyz":                      true,
+		"GET /ping":                    true,
+		"GET /cart":                    false,
+		"HTTP GET /product/OLJCESPC7Z": false,
+		"frontend":                     false,
+	} {
+		if got := HealthChecks.MatchString(op); got != want {
+			t.Errorf("HealthChecks matches %q = %v", op, got)
+		}
+	}
+}
+
+// TestSampleTraces checks that the default stages reproduce the sample
+// trace file, which the Jaeger preprocessing produced.
+func TestSampleTraces(t *testing.T) {
+	var sample common.TraceData
+	if err := common.LoadJSONFile("../app.json", &sample); err != nil {
+		t.Fatal(err)
+	}
+	var traces common.TraceData
+	if err := common.LoadJSONFile("../app.json", &traces); err != nil {
+		t.Fatal(err)
+	}
+	for i := range traces.Data {
+		for j := range traces.Data[i].Spans {
+			span := &traces.Data[i].Spans[j]
+			span.ServiceName, span.ParentService, span.ParentOperation = "", "", ""
+		}
+	}
+	if !reflect.DeepEqual(Run(&traces, Default(loadRules(t))...), &sample) {
+		t.Errorf("default stages do not reproduce ../app.json")
+	}
+}
@@ -1,104 +1,24 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
+	"optimizer/common"
+	"optimizer/jaeger"
+	"time"
 )
 
-const jaegerBaseURL = "http://localhost:16686/api"
-
-// 定義 JSON 結構
-type ServicesResponse struct {
-	Data []string `json:"data"`
-}
-
-type OperationsResponse struct {
-	Data []string `json:"data"`
-}
-
-type TraceResponse struct {
-	Data []struct {
-		Spans []struct {
-			SpanID        string `json:"spanID"`
-			OperationName string `json:"operationName"`
-			Duration      int64  `json:"duration"` // 微秒 (µs)
-			References    []struct {
-				RefType string `json:"refType"`
-				SpanID  string `json:"spanID"`
-			} `json:"references"`
-		} `json:"spans"`
-	} `json:"data"`
-}
-
-// 取得所有 service
-func getServices() ([]string, error) {
-	resp, err := http.Get(fmt.Sprintf("%s/services", jaegerBaseURL))
-	if err != nil {
-		return nil, fmt.Errorf("error fetching services: %v", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
+// getOperationSelfDuration returns the mean self duration of operation of
+// service, by the service rules attribute its spans to.
+func getOperationSelfDuration(ctx context.Context, client *jaeger.Client, rules *common.ServiceRules, service, operation string, lookback time.Duration, limit int) (map[string]int64, error) {
+	result, err := client.Traces(ctx, jaeger.Query{Service: service, Operation: operation, Lookback: lookback, Limit: limit})
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
-	}
-
-	var result ServicesResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("error parsing JSON: %v", err)
-	}
-
-	return result.Data, nil
-}
-
-// 取得某個 service 的所有 operations
-func getOperations(service string) ([]string, error) {
-	resp, err := http.Get(fmt.Sprintf("%s/services/%s/operations", jaegerBaseURL, service))
-	if err != nil {
-		return nil, fmt.Errorf("error fetching operations for %s: %v", service, err)
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response: %v", err)
-	}
-
-	var result OperationsResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("error parsing JSON: %v", err)
-	}
-
-	return result.Data, nil
-}
-
-// 計算某個 API operation 的 self duration
-func getOperationSelfDuration(service, operation string) (int64, error) {
-	// url := fmt.Sprintf("%s/traces?service=%s&operation=%s&limit=10", jaegerBaseURL, service, operation)
-	encodedOperation := url.QueryEscape(operation)
-	url := fmt.Sprintf("%s/traces?service=%s&operation=%s&limit=500", jaegerBaseURL, service, encodedOperation)
-	resp, err := http.Get(url)
-	if err != nil {
-		return 0, fmt.Errorf("error fetching traces: %v", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return 0, fmt.Errorf("error reading response: %v", err)
-	}
-
-	var result TraceResponse
-	if err := json.Unmarshal(body, &result); err != nil {
-		return 0, fmt.Errorf("error parsing JSON: %v", err)
+		return nil, err
 	}
 
 	// 計算 self duration
-	var totalSelfDuration int64
-	var count int64
+	totalSelfDuration := make(map[string]int64)
+	count := make(map[string]int64)
 
 	for _, trace := range result.Data {
 		spanMap := make(map[string]int64)
@@ -107,7 +27,7 @@ func getOperationSelfDuration(service, operation string) (int64, error) {
 		for _, span := range trace.Spans {
 			spanMap[span.SpanID] = span.Duration
 			for _, ref := range span.References {
-				if ref.RefType == "CHILD_OF" {
+				if ref.RefType == common.RefChildOf {
 					childMap[ref.SpanID] = append(childMap[ref.SpanID], span.SpanID)
 				}
 			}
@@ -120,51 +40,61 @@ func getOperationSelfDuration(service, operation string) (int64, error) {
 					childDuration += spanMap[childID]
 				}
 				selfDuration := span.Duration - childDuration
-				totalSelfDuration += selfDuration
-				count++
+				target := service
+				if s, ok := rules.ServiceOf(span); ok {
+					target = s
+				}
+				totalSelfDuration[target] += selfDuration
+				count[target]++
 			}
 		}
 	}
 
-	if count == 0 {
-		return 0, fmt.Errorf("no traces found for %s/%s", service, operation)
+	if len(count) == 0 {
+		return nil, fmt.Errorf("no traces found for %s/%s", service, operation)
 	}
 
-	return totalSelfDuration / count, nil
+	means := make(map[string]int64, len(count))
+	for target, n := range count {
+		means[target] = totalSelfDuration[target] / n
+	}
+	return means, nil
 }
 
-// 遍歷所有 service 和 operations，計算 self duration
-func GetProcessingTime(filename string) {
-	services, err := getServices()
+// GetProcessingTime measures the mean self duration of every operation of
+// every service over up to limit traces reported during the last lookback,
+// and writes the result to filename. Operations rules attribute to a virtual
+// service are listed under it.
+func GetProcessingTime(ctx context.Context, client *jaeger.Client, rules *common.ServiceRules, lookback time.Duration, limit int, filename string) error {
+	services, err := client.Services(ctx)
 	if err != nil {
-		fmt.Printf("Error getting services: %v\n", err)
-		return
+		return fmt.Errorf("getting services: %w", err)
 	}
 
 	selfDurations := make(map[string]map[string]int64)
-	selfDurations["redis-cart"] = make(map[string]int64)
 	for _, service := range services {
 
 		selfDurations[service] = make(map[string]int64)
-		operations, err := getOperations(service)
+		operations, err := client.Operations(ctx, service)
 		if err != nil {
 			fmt.Printf("Error getting operations for %s: %v\n", service, err)
 			continue
 		}
 
 		for _, operation := range operations {
-			selfDuration, err := getOperationSelfDuration(service, operation)
+			means, err := getOperationSelfDuration(ctx, client, rules, service, operation, lookback, limit)
 			if err != nil {
 				fmt.Printf("Error getting self duration for %s/%s: %v\n", service, operation, err)
 				continue
 			}
 
-			if operation == "RedisAddItem" || operation == "RedisEmptyCart" || operation == "RedisGetCart" {
-				selfDurations["redis-cart"][operation] = selfDuration
-			} else {
-				selfDurations[service][operation] = selfDuration
+			for target, selfDuration := range means {
+				if selfDurations[target] == nil {
+					selfDurations[target] = make(map[string]int64)
+				}
+				selfDurations[target][operation] = selfDuration
 			}
-			// fmt.Printf("Self Duration for %s:%s %d µs\n", service, operation, selfDuration) 
+			// fmt.Printf("Self Duration for %s:%s %d µs\n", service, operation, selfDuration)
 		}
 	}
 
@@ -173,4 +103,5 @@ func GetProcessingTime(filename string) {
 
 	// print the selfDurations
 	printJSON(selfDurations, filename)
+	return nil
 }
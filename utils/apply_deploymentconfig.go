@@ -15,50 +15,31 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-const namespace = "online-boutique"
-
-var targetDeployments = map[string]bool{
-	"cartservice":           true,
-	"checkoutservice":       true,
-	"currencyservice":       true,
-	"emailservice":          true,
-	"frontend":              true,
-	"paymentservice":        true,
-	"productcatalogservice": true,
-	"recommendationservice": true,
-	"redis-cart":            true,
-	"shippingservice":       true,
-}
+// defaultNamespace is used when the topology does not name one.
+const defaultNamespace = "online-boutique"
 
 var renamed = false
 
-func renameDeployments() {
-	fmt.Println("enter renameDeployments()")
-	// 嘗試讀取 kubeconfig
-	kubeconfigPath := os.Getenv("HOME") + "/.kube/config"
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
-	if err != nil {
-		log.Fatalf("Failed to load kubeconfig: %v", err)
-	}
-
-	// 建立 Kubernetes client
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		log.Fatalf("Failed to create clientset: %v", err)
+func namespaceOf(topo *common.Topology) string {
+	if topo.Namespace != "" {
+		return topo.Namespace
 	}
+	return defaultNamespace
+}
 
-	deploymentsClient := clientset.AppsV1().Deployments(namespace)
+func renameDeployments(clientset *kubernetes.Clientset, topo *common.Topology) error {
+	deploymentsClient := clientset.AppsV1().Deployments(namespaceOf(topo))
 	deployList, err := deploymentsClient.List(context.TODO(), metav1.ListOptions{})
 	if err != nil {
-		log.Fatalf("Failed to list deployments: %v", err)
+		return fmt.Errorf("failed to list deployments: %w", err)
 	}
 
 	for _, deploy := range deployList.Items {
 		originalName := deploy.Name
-		if !targetDeployments[originalName] {
+		if !topo.HasService(originalName) {
 			continue // 忽略不在指定清單內的 Deployment
 		}
-		for _, node := range common.Nodes {
+		for _, node := range topo.NodeNames() {
 			newDeploy := deploy.DeepCopy()
 			newDeploy.Name = fmt.Sprintf("%s-%s", originalName, node)
 			newDeploy.Spec.Template.Spec.NodeSelector = map[string]string{"kubernetes.io/hostname": node}
@@ -80,43 +61,52 @@ func renameDeployments() {
 			log.Printf("Deleted original deployment %s", originalName)
 		}
 	}
+	return nil
 }
 
-func UpDateDeploymentsByJSON(filename string) {
+// DefaultKubeconfig returns the kubeconfig path kubectl uses by default.
+func DefaultKubeconfig() string {
+	return os.Getenv("HOME") + "/.kube/config"
+}
+
+// UpDateDeploymentsByJSON applies the deployment config in filename to the
+// cluster reachable through kubeconfigPath.
+func UpDateDeploymentsByJSON(filename, kubeconfigPath string, topo *common.Topology) error {
 	// 嘗試讀取 kubeconfig
-	kubeconfigPath := os.Getenv("HOME") + "/.kube/config"
 	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
 	if err != nil {
-		log.Fatalf("Failed to load kubeconfig: %v", err)
+		return fmt.Errorf("failed to load kubeconfig: %w", err)
 	}
 
 	// 建立 Kubernetes client
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		log.Fatalf("Failed to create clientset: %v", err)
+		return fmt.Errorf("failed to create clientset: %w", err)
 	}
 
 	var deploymentConfig map[string]map[string]int
 	if err := common.LoadJSONFile(filename, &deploymentConfig); err != nil {
-		fmt.Println("Error loading path_durations.json:", err)
-		return
+		return fmt.Errorf("loading %s: %w", filename, err)
 	}
 
-	err = UpdateDeployments(clientset, deploymentConfig)
+	err = UpdateDeployments(clientset, deploymentConfig, topo)
 	if err != nil {
-		log.Fatalf("Error updating deployments: %v", err)
+		return fmt.Errorf("updating deployments: %w", err)
 	}
 
 	fmt.Println("Successfully updated all deployments")
+	return nil
 }
 
-func UpdateDeployments(clientset *kubernetes.Clientset, deploymentConfig map[string]map[string]int) error {
+func UpdateDeployments(clientset *kubernetes.Clientset, deploymentConfig map[string]map[string]int, topo *common.Topology) error {
 	if !renamed {
-		renameDeployments()
+		if err := renameDeployments(clientset, topo); err != nil {
+			return err
+		}
 		renamed = true
 	}
 
-	deploymentsClient := clientset.AppsV1().Deployments(namespace)
+	deploymentsClient := clientset.AppsV1().Deployments(namespaceOf(topo))
 
 	for node, services := range deploymentConfig {
 		for service, replicas := range services {